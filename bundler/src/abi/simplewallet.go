@@ -0,0 +1,1219 @@
+// Code generated - DO NOT EDIT.
+// This file is a generated binding and any manual changes will be lost.
+
+package abi
+
+import (
+	"errors"
+	"math/big"
+	"strings"
+
+	ethereum "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/event"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var (
+	_ = errors.New
+	_ = big.NewInt
+	_ = strings.NewReader
+	_ = ethereum.NotFound
+	_ = bind.Bind
+	_ = common.Big1
+	_ = types.BloomLookup
+	_ = event.NewSubscription
+)
+
+// SimpleWalletMetaData contains all meta data concerning the SimpleWallet contract.
+var SimpleWalletMetaData = &bind.MetaData{
+	ABI: "[{\"inputs\":[{\"internalType\":\"contractEntryPoint\",\"name\":\"_entryPointAddress\",\"type\":\"address\"},{\"internalType\":\"address\",\"name\":\"_owner\",\"type\":\"address\"},{\"internalType\":\"address\",\"name\":\"_gasToken\",\"type\":\"address\"},{\"internalType\":\"address\",\"name\":\"_approveFor\",\"type\":\"address\"},{\"internalType\":\"uint256\",\"name\":\"_amount\",\"type\":\"uint256\"},{\"internalType\":\"address\",\"name\":\"_nativeTokenPaymaster\",\"type\":\"address\"}],\"stateMutability\":\"nonpayable\",\"type\":\"constructor\"},{\"anonymous\":false,\"inputs\":[{\"indexed\":true,\"internalType\":\"address\",\"name\":\"oldEntryPoint\",\"type\":\"address\"},{\"indexed\":true,\"internalType\":\"address\",\"name\":\"newEntryPoint\",\"type\":\"address\"}],\"name\":\"EntryPointChanged\",\"type\":\"event\"},{\"anonymous\":false,\"inputs\":[{\"indexed\":true,\"internalType\":\"address\",\"name\":\"oldOwner\",\"type\":\"address\"},{\"indexed\":true,\"internalType\":\"address\",\"name\":\"newOwner\",\"type\":\"address\"}],\"name\":\"OwnerChanged\",\"type\":\"event\"},{\"anonymous\":false,\"inputs\":[{\"indexed\":true,\"internalType\":\"address\",\"name\":\"oldPaymaster\",\"type\":\"address\"},{\"indexed\":true,\"internalType\":\"address\",\"name\":\"newPaymaster\",\"type\":\"address\"}],\"name\":\"PaymasterChanged\",\"type\":\"event\"},{\"inputs\":[],\"name\":\"NAME\",\"outputs\":[{\"internalType\":\"string\",\"name\":\"\",\"type\":\"string\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[],\"name\":\"VERSION\",\"outputs\":[{\"internalType\":\"string\",\"name\":\"\",\"type\":\"string\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[],\"name\":\"addDeposit\",\"outputs\":[],\"stateMutability\":\"payable\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"address\",\"name\":\"newOwner\",\"type\":\"address\"}],\"name\":\"changeOwner\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"address\",\"name\":\"newPaymaster\",\"type\":\"address\"}],\"name\":\"changePaymaster\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[],\"name\":\"entryPoint\",\"outputs\":[{\"internalType\":\"contractEntryPoint\",\"name\":\"\",\"type\":\"address\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"address\",\"name\":\"dest\",\"type\":\"address\"},{\"internalType\":\"uint256\",\"name\":\"value\",\"type\":\"uint256\"},{\"internalType\":\"bytes\",\"name\":\"func\",\"type\":\"bytes\"}],\"name\":\"exec\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"address[]\",\"name\":\"dest\",\"type\":\"address[]\"},{\"internalType\":\"bytes[]\",\"name\":\"func\",\"type\":\"bytes[]\"}],\"name\":\"execBatch\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"address\",\"name\":\"dest\",\"type\":\"address\"},{\"internalType\":\"uint256\",\"name\":\"value\",\"type\":\"uint256\"},{\"internalType\":\"bytes\",\"name\":\"func\",\"type\":\"bytes\"}],\"name\":\"execFromEntryPoint\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[],\"name\":\"getDeposit\",\"outputs\":[{\"internalType\":\"uint256\",\"name\":\"\",\"type\":\"uint256\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[],\"name\":\"nativeTokenPaymaster\",\"outputs\":[{\"internalType\":\"address\",\"name\":\"\",\"type\":\"address\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[],\"name\":\"nonce\",\"outputs\":[{\"internalType\":\"uint256\",\"name\":\"\",\"type\":\"uint256\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"address\",\"name\":\"\",\"type\":\"address\"},{\"internalType\":\"address\",\"name\":\"\",\"type\":\"address\"},{\"internalType\":\"uint256[]\",\"name\":\"\",\"type\":\"uint256[]\"},{\"internalType\":\"uint256[]\",\"name\":\"\",\"type\":\"uint256[]\"},{\"internalType\":\"bytes\",\"name\":\"\",\"type\":\"bytes\"}],\"name\":\"onERC1155BatchReceived\",\"outputs\":[{\"internalType\":\"bytes4\",\"name\":\"\",\"type\":\"bytes4\"}],\"stateMutability\":\"pure\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"address\",\"name\":\"\",\"type\":\"address\"},{\"internalType\":\"address\",\"name\":\"\",\"type\":\"address\"},{\"internalType\":\"uint256\",\"name\":\"\",\"type\":\"uint256\"},{\"internalType\":\"uint256\",\"name\":\"\",\"type\":\"uint256\"},{\"internalType\":\"bytes\",\"name\":\"\",\"type\":\"bytes\"}],\"name\":\"onERC1155Received\",\"outputs\":[{\"internalType\":\"bytes4\",\"name\":\"\",\"type\":\"bytes4\"}],\"stateMutability\":\"pure\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"address\",\"name\":\"\",\"type\":\"address\"},{\"internalType\":\"address\",\"name\":\"\",\"type\":\"address\"},{\"internalType\":\"uint256\",\"name\":\"\",\"type\":\"uint256\"},{\"internalType\":\"bytes\",\"name\":\"\",\"type\":\"bytes\"}],\"name\":\"onERC721Received\",\"outputs\":[{\"internalType\":\"bytes4\",\"name\":\"\",\"type\":\"bytes4\"}],\"stateMutability\":\"pure\",\"type\":\"function\"},{\"inputs\":[],\"name\":\"owner\",\"outputs\":[{\"internalType\":\"address\",\"name\":\"\",\"type\":\"address\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"bytes4\",\"name\":\"interfaceId\",\"type\":\"bytes4\"}],\"name\":\"supportsInterface\",\"outputs\":[{\"internalType\":\"bool\",\"name\":\"\",\"type\":\"bool\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"address\",\"name\":\"\",\"type\":\"address\"},{\"internalType\":\"address\",\"name\":\"\",\"type\":\"address\"},{\"internalType\":\"address\",\"name\":\"\",\"type\":\"address\"},{\"internalType\":\"uint256\",\"name\":\"\",\"type\":\"uint256\"},{\"internalType\":\"bytes\",\"name\":\"\",\"type\":\"bytes\"},{\"internalType\":\"bytes\",\"name\":\"\",\"type\":\"bytes\"}],\"name\":\"tokensReceived\",\"outputs\":[],\"stateMutability\":\"pure\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"addresspayable\",\"name\":\"dest\",\"type\":\"address\"},{\"internalType\":\"uint256\",\"name\":\"amount\",\"type\":\"uint256\"}],\"name\":\"transfer\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"address\",\"name\":\"newEntryPoint\",\"type\":\"address\"}],\"name\":\"updateEntryPoint\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"addresspayable\",\"name\":\"withdrawAddress\",\"type\":\"address\"},{\"internalType\":\"uint256\",\"name\":\"amount\",\"type\":\"uint256\"}],\"name\":\"withdrawDepositTo\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"stateMutability\":\"payable\",\"type\":\"receive\"}]",
+}
+
+// SimpleWalletABI is the input ABI used to generate the binding from.
+// Deprecated: Use SimpleWalletMetaData.ABI instead.
+var SimpleWalletABI = SimpleWalletMetaData.ABI
+
+// SimpleWallet is an auto generated Go binding around an Ethereum contract.
+type SimpleWallet struct {
+	SimpleWalletCaller     // Read-only binding to the contract
+	SimpleWalletTransactor // Write-only binding to the contract
+	SimpleWalletFilterer   // Log filterer for contract events
+}
+
+// SimpleWalletCaller is an auto generated read-only Go binding around an Ethereum contract.
+type SimpleWalletCaller struct {
+	contract *bind.BoundContract // Generic contract wrapper for the low level calls
+}
+
+// SimpleWalletTransactor is an auto generated write-only Go binding around an Ethereum contract.
+type SimpleWalletTransactor struct {
+	contract *bind.BoundContract // Generic contract wrapper for the low level calls
+}
+
+// SimpleWalletFilterer is an auto generated log filtering Go binding around an Ethereum contract events.
+type SimpleWalletFilterer struct {
+	contract *bind.BoundContract // Generic contract wrapper for the low level calls
+}
+
+// SimpleWalletSession is an auto generated Go binding around an Ethereum contract,
+// with pre-set call and transact options.
+type SimpleWalletSession struct {
+	Contract     *SimpleWallet     // Generic contract binding to set the session for
+	CallOpts     bind.CallOpts     // Call options to use throughout this session
+	TransactOpts bind.TransactOpts // Transaction auth options to use throughout this session
+}
+
+// SimpleWalletCallerSession is an auto generated read-only Go binding around an Ethereum contract,
+// with pre-set call options.
+type SimpleWalletCallerSession struct {
+	Contract *SimpleWalletCaller // Generic contract caller binding to set the session for
+	CallOpts bind.CallOpts       // Call options to use throughout this session
+}
+
+// SimpleWalletTransactorSession is an auto generated write-only Go binding around an Ethereum contract,
+// with pre-set transact options.
+type SimpleWalletTransactorSession struct {
+	Contract     *SimpleWalletTransactor // Generic contract transactor binding to set the session for
+	TransactOpts bind.TransactOpts       // Transaction auth options to use throughout this session
+}
+
+// SimpleWalletRaw is an auto generated low-level Go binding around an Ethereum contract.
+type SimpleWalletRaw struct {
+	Contract *SimpleWallet // Generic contract binding to access the raw methods on
+}
+
+// SimpleWalletCallerRaw is an auto generated low-level read-only Go binding around an Ethereum contract.
+type SimpleWalletCallerRaw struct {
+	Contract *SimpleWalletCaller // Generic read-only contract binding to access the raw methods on
+}
+
+// SimpleWalletTransactorRaw is an auto generated low-level write-only Go binding around an Ethereum contract.
+type SimpleWalletTransactorRaw struct {
+	Contract *SimpleWalletTransactor // Generic write-only contract binding to access the raw methods on
+}
+
+// NewSimpleWallet creates a new instance of SimpleWallet, bound to a specific deployed contract.
+func NewSimpleWallet(address common.Address, backend bind.ContractBackend) (*SimpleWallet, error) {
+	contract, err := bindSimpleWallet(address, backend, backend, backend)
+	if err != nil {
+		return nil, err
+	}
+	return &SimpleWallet{SimpleWalletCaller: SimpleWalletCaller{contract: contract}, SimpleWalletTransactor: SimpleWalletTransactor{contract: contract}, SimpleWalletFilterer: SimpleWalletFilterer{contract: contract}}, nil
+}
+
+// NewSimpleWalletCaller creates a new read-only instance of SimpleWallet, bound to a specific deployed contract.
+func NewSimpleWalletCaller(address common.Address, caller bind.ContractCaller) (*SimpleWalletCaller, error) {
+	contract, err := bindSimpleWallet(address, caller, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &SimpleWalletCaller{contract: contract}, nil
+}
+
+// NewSimpleWalletTransactor creates a new write-only instance of SimpleWallet, bound to a specific deployed contract.
+func NewSimpleWalletTransactor(address common.Address, transactor bind.ContractTransactor) (*SimpleWalletTransactor, error) {
+	contract, err := bindSimpleWallet(address, nil, transactor, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &SimpleWalletTransactor{contract: contract}, nil
+}
+
+// NewSimpleWalletFilterer creates a new log filterer instance of SimpleWallet, bound to a specific deployed contract.
+func NewSimpleWalletFilterer(address common.Address, filterer bind.ContractFilterer) (*SimpleWalletFilterer, error) {
+	contract, err := bindSimpleWallet(address, nil, nil, filterer)
+	if err != nil {
+		return nil, err
+	}
+	return &SimpleWalletFilterer{contract: contract}, nil
+}
+
+// bindSimpleWallet binds a generic wrapper to an already deployed contract.
+func bindSimpleWallet(address common.Address, caller bind.ContractCaller, transactor bind.ContractTransactor, filterer bind.ContractFilterer) (*bind.BoundContract, error) {
+	parsed, err := abi.JSON(strings.NewReader(SimpleWalletABI))
+	if err != nil {
+		return nil, err
+	}
+	return bind.NewBoundContract(address, parsed, caller, transactor, filterer), nil
+}
+
+// Call invokes the (constant) contract method with params as input values and
+// sets the output to result. The result type might be a single field for simple
+// returns, a slice of interfaces for anonymous returns and a struct for named
+// returns.
+func (_SimpleWallet *SimpleWalletRaw) Call(opts *bind.CallOpts, result *[]interface{}, method string, params ...interface{}) error {
+	return _SimpleWallet.Contract.SimpleWalletCaller.contract.Call(opts, result, method, params...)
+}
+
+// Transfer initiates a plain transaction to move funds to the contract, calling
+// its default method if one is available.
+func (_SimpleWallet *SimpleWalletRaw) Transfer(opts *bind.TransactOpts) (*types.Transaction, error) {
+	return _SimpleWallet.Contract.SimpleWalletTransactor.contract.Transfer(opts)
+}
+
+// Transact invokes the (paid) contract method with params as input values.
+func (_SimpleWallet *SimpleWalletRaw) Transact(opts *bind.TransactOpts, method string, params ...interface{}) (*types.Transaction, error) {
+	return _SimpleWallet.Contract.SimpleWalletTransactor.contract.Transact(opts, method, params...)
+}
+
+// Call invokes the (constant) contract method with params as input values and
+// sets the output to result. The result type might be a single field for simple
+// returns, a slice of interfaces for anonymous returns and a struct for named
+// returns.
+func (_SimpleWallet *SimpleWalletCallerRaw) Call(opts *bind.CallOpts, result *[]interface{}, method string, params ...interface{}) error {
+	return _SimpleWallet.Contract.contract.Call(opts, result, method, params...)
+}
+
+// Transfer initiates a plain transaction to move funds to the contract, calling
+// its default method if one is available.
+func (_SimpleWallet *SimpleWalletTransactorRaw) Transfer(opts *bind.TransactOpts) (*types.Transaction, error) {
+	return _SimpleWallet.Contract.contract.Transfer(opts)
+}
+
+// Transact invokes the (paid) contract method with params as input values.
+func (_SimpleWallet *SimpleWalletTransactorRaw) Transact(opts *bind.TransactOpts, method string, params ...interface{}) (*types.Transaction, error) {
+	return _SimpleWallet.Contract.contract.Transact(opts, method, params...)
+}
+
+// NAME is a free data retrieval call binding the contract method 0xa3f4df7e.
+//
+// Solidity: function NAME() view returns(string)
+func (_SimpleWallet *SimpleWalletCaller) NAME(opts *bind.CallOpts) (string, error) {
+	var out []interface{}
+	err := _SimpleWallet.contract.Call(opts, &out, "NAME")
+
+	if err != nil {
+		return *new(string), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new(string)).(*string)
+
+	return out0, err
+
+}
+
+// NAME is a free data retrieval call binding the contract method 0xa3f4df7e.
+//
+// Solidity: function NAME() view returns(string)
+func (_SimpleWallet *SimpleWalletSession) NAME() (string, error) {
+	return _SimpleWallet.Contract.NAME(&_SimpleWallet.CallOpts)
+}
+
+// NAME is a free data retrieval call binding the contract method 0xa3f4df7e.
+//
+// Solidity: function NAME() view returns(string)
+func (_SimpleWallet *SimpleWalletCallerSession) NAME() (string, error) {
+	return _SimpleWallet.Contract.NAME(&_SimpleWallet.CallOpts)
+}
+
+// VERSION is a free data retrieval call binding the contract method 0xffa1ad74.
+//
+// Solidity: function VERSION() view returns(string)
+func (_SimpleWallet *SimpleWalletCaller) VERSION(opts *bind.CallOpts) (string, error) {
+	var out []interface{}
+	err := _SimpleWallet.contract.Call(opts, &out, "VERSION")
+
+	if err != nil {
+		return *new(string), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new(string)).(*string)
+
+	return out0, err
+
+}
+
+// VERSION is a free data retrieval call binding the contract method 0xffa1ad74.
+//
+// Solidity: function VERSION() view returns(string)
+func (_SimpleWallet *SimpleWalletSession) VERSION() (string, error) {
+	return _SimpleWallet.Contract.VERSION(&_SimpleWallet.CallOpts)
+}
+
+// VERSION is a free data retrieval call binding the contract method 0xffa1ad74.
+//
+// Solidity: function VERSION() view returns(string)
+func (_SimpleWallet *SimpleWalletCallerSession) VERSION() (string, error) {
+	return _SimpleWallet.Contract.VERSION(&_SimpleWallet.CallOpts)
+}
+
+// EntryPoint is a free data retrieval call binding the contract method 0xb0d691fe.
+//
+// Solidity: function entryPoint() view returns(address)
+func (_SimpleWallet *SimpleWalletCaller) EntryPoint(opts *bind.CallOpts) (common.Address, error) {
+	var out []interface{}
+	err := _SimpleWallet.contract.Call(opts, &out, "entryPoint")
+
+	if err != nil {
+		return *new(common.Address), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new(common.Address)).(*common.Address)
+
+	return out0, err
+
+}
+
+// EntryPoint is a free data retrieval call binding the contract method 0xb0d691fe.
+//
+// Solidity: function entryPoint() view returns(address)
+func (_SimpleWallet *SimpleWalletSession) EntryPoint() (common.Address, error) {
+	return _SimpleWallet.Contract.EntryPoint(&_SimpleWallet.CallOpts)
+}
+
+// EntryPoint is a free data retrieval call binding the contract method 0xb0d691fe.
+//
+// Solidity: function entryPoint() view returns(address)
+func (_SimpleWallet *SimpleWalletCallerSession) EntryPoint() (common.Address, error) {
+	return _SimpleWallet.Contract.EntryPoint(&_SimpleWallet.CallOpts)
+}
+
+// GetDeposit is a free data retrieval call binding the contract method 0xc399ec88.
+//
+// Solidity: function getDeposit() view returns(uint256)
+func (_SimpleWallet *SimpleWalletCaller) GetDeposit(opts *bind.CallOpts) (*big.Int, error) {
+	var out []interface{}
+	err := _SimpleWallet.contract.Call(opts, &out, "getDeposit")
+
+	if err != nil {
+		return *new(*big.Int), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new(*big.Int)).(**big.Int)
+
+	return out0, err
+
+}
+
+// GetDeposit is a free data retrieval call binding the contract method 0xc399ec88.
+//
+// Solidity: function getDeposit() view returns(uint256)
+func (_SimpleWallet *SimpleWalletSession) GetDeposit() (*big.Int, error) {
+	return _SimpleWallet.Contract.GetDeposit(&_SimpleWallet.CallOpts)
+}
+
+// GetDeposit is a free data retrieval call binding the contract method 0xc399ec88.
+//
+// Solidity: function getDeposit() view returns(uint256)
+func (_SimpleWallet *SimpleWalletCallerSession) GetDeposit() (*big.Int, error) {
+	return _SimpleWallet.Contract.GetDeposit(&_SimpleWallet.CallOpts)
+}
+
+// NativeTokenPaymaster is a free data retrieval call binding the contract method 0x727d4d2c.
+//
+// Solidity: function nativeTokenPaymaster() view returns(address)
+func (_SimpleWallet *SimpleWalletCaller) NativeTokenPaymaster(opts *bind.CallOpts) (common.Address, error) {
+	var out []interface{}
+	err := _SimpleWallet.contract.Call(opts, &out, "nativeTokenPaymaster")
+
+	if err != nil {
+		return *new(common.Address), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new(common.Address)).(*common.Address)
+
+	return out0, err
+
+}
+
+// NativeTokenPaymaster is a free data retrieval call binding the contract method 0x727d4d2c.
+//
+// Solidity: function nativeTokenPaymaster() view returns(address)
+func (_SimpleWallet *SimpleWalletSession) NativeTokenPaymaster() (common.Address, error) {
+	return _SimpleWallet.Contract.NativeTokenPaymaster(&_SimpleWallet.CallOpts)
+}
+
+// NativeTokenPaymaster is a free data retrieval call binding the contract method 0x727d4d2c.
+//
+// Solidity: function nativeTokenPaymaster() view returns(address)
+func (_SimpleWallet *SimpleWalletCallerSession) NativeTokenPaymaster() (common.Address, error) {
+	return _SimpleWallet.Contract.NativeTokenPaymaster(&_SimpleWallet.CallOpts)
+}
+
+// Nonce is a free data retrieval call binding the contract method 0xaffed0e0.
+//
+// Solidity: function nonce() view returns(uint256)
+func (_SimpleWallet *SimpleWalletCaller) Nonce(opts *bind.CallOpts) (*big.Int, error) {
+	var out []interface{}
+	err := _SimpleWallet.contract.Call(opts, &out, "nonce")
+
+	if err != nil {
+		return *new(*big.Int), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new(*big.Int)).(**big.Int)
+
+	return out0, err
+
+}
+
+// Nonce is a free data retrieval call binding the contract method 0xaffed0e0.
+//
+// Solidity: function nonce() view returns(uint256)
+func (_SimpleWallet *SimpleWalletSession) Nonce() (*big.Int, error) {
+	return _SimpleWallet.Contract.Nonce(&_SimpleWallet.CallOpts)
+}
+
+// Nonce is a free data retrieval call binding the contract method 0xaffed0e0.
+//
+// Solidity: function nonce() view returns(uint256)
+func (_SimpleWallet *SimpleWalletCallerSession) Nonce() (*big.Int, error) {
+	return _SimpleWallet.Contract.Nonce(&_SimpleWallet.CallOpts)
+}
+
+// OnERC1155BatchReceived is a free data retrieval call binding the contract method 0xbc197c81.
+//
+// Solidity: function onERC1155BatchReceived(address , address , uint256[] , uint256[] , bytes ) pure returns(bytes4)
+func (_SimpleWallet *SimpleWalletCaller) OnERC1155BatchReceived(opts *bind.CallOpts, arg0 common.Address, arg1 common.Address, arg2 []*big.Int, arg3 []*big.Int, arg4 []byte) ([4]byte, error) {
+	var out []interface{}
+	err := _SimpleWallet.contract.Call(opts, &out, "onERC1155BatchReceived", arg0, arg1, arg2, arg3, arg4)
+
+	if err != nil {
+		return *new([4]byte), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new([4]byte)).(*[4]byte)
+
+	return out0, err
+
+}
+
+// OnERC1155BatchReceived is a free data retrieval call binding the contract method 0xbc197c81.
+//
+// Solidity: function onERC1155BatchReceived(address , address , uint256[] , uint256[] , bytes ) pure returns(bytes4)
+func (_SimpleWallet *SimpleWalletSession) OnERC1155BatchReceived(arg0 common.Address, arg1 common.Address, arg2 []*big.Int, arg3 []*big.Int, arg4 []byte) ([4]byte, error) {
+	return _SimpleWallet.Contract.OnERC1155BatchReceived(&_SimpleWallet.CallOpts, arg0, arg1, arg2, arg3, arg4)
+}
+
+// OnERC1155BatchReceived is a free data retrieval call binding the contract method 0xbc197c81.
+//
+// Solidity: function onERC1155BatchReceived(address , address , uint256[] , uint256[] , bytes ) pure returns(bytes4)
+func (_SimpleWallet *SimpleWalletCallerSession) OnERC1155BatchReceived(arg0 common.Address, arg1 common.Address, arg2 []*big.Int, arg3 []*big.Int, arg4 []byte) ([4]byte, error) {
+	return _SimpleWallet.Contract.OnERC1155BatchReceived(&_SimpleWallet.CallOpts, arg0, arg1, arg2, arg3, arg4)
+}
+
+// OnERC1155Received is a free data retrieval call binding the contract method 0xf23a6e61.
+//
+// Solidity: function onERC1155Received(address , address , uint256 , uint256 , bytes ) pure returns(bytes4)
+func (_SimpleWallet *SimpleWalletCaller) OnERC1155Received(opts *bind.CallOpts, arg0 common.Address, arg1 common.Address, arg2 *big.Int, arg3 *big.Int, arg4 []byte) ([4]byte, error) {
+	var out []interface{}
+	err := _SimpleWallet.contract.Call(opts, &out, "onERC1155Received", arg0, arg1, arg2, arg3, arg4)
+
+	if err != nil {
+		return *new([4]byte), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new([4]byte)).(*[4]byte)
+
+	return out0, err
+
+}
+
+// OnERC1155Received is a free data retrieval call binding the contract method 0xf23a6e61.
+//
+// Solidity: function onERC1155Received(address , address , uint256 , uint256 , bytes ) pure returns(bytes4)
+func (_SimpleWallet *SimpleWalletSession) OnERC1155Received(arg0 common.Address, arg1 common.Address, arg2 *big.Int, arg3 *big.Int, arg4 []byte) ([4]byte, error) {
+	return _SimpleWallet.Contract.OnERC1155Received(&_SimpleWallet.CallOpts, arg0, arg1, arg2, arg3, arg4)
+}
+
+// OnERC1155Received is a free data retrieval call binding the contract method 0xf23a6e61.
+//
+// Solidity: function onERC1155Received(address , address , uint256 , uint256 , bytes ) pure returns(bytes4)
+func (_SimpleWallet *SimpleWalletCallerSession) OnERC1155Received(arg0 common.Address, arg1 common.Address, arg2 *big.Int, arg3 *big.Int, arg4 []byte) ([4]byte, error) {
+	return _SimpleWallet.Contract.OnERC1155Received(&_SimpleWallet.CallOpts, arg0, arg1, arg2, arg3, arg4)
+}
+
+// OnERC721Received is a free data retrieval call binding the contract method 0x150b7a02.
+//
+// Solidity: function onERC721Received(address , address , uint256 , bytes ) pure returns(bytes4)
+func (_SimpleWallet *SimpleWalletCaller) OnERC721Received(opts *bind.CallOpts, arg0 common.Address, arg1 common.Address, arg2 *big.Int, arg3 []byte) ([4]byte, error) {
+	var out []interface{}
+	err := _SimpleWallet.contract.Call(opts, &out, "onERC721Received", arg0, arg1, arg2, arg3)
+
+	if err != nil {
+		return *new([4]byte), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new([4]byte)).(*[4]byte)
+
+	return out0, err
+
+}
+
+// OnERC721Received is a free data retrieval call binding the contract method 0x150b7a02.
+//
+// Solidity: function onERC721Received(address , address , uint256 , bytes ) pure returns(bytes4)
+func (_SimpleWallet *SimpleWalletSession) OnERC721Received(arg0 common.Address, arg1 common.Address, arg2 *big.Int, arg3 []byte) ([4]byte, error) {
+	return _SimpleWallet.Contract.OnERC721Received(&_SimpleWallet.CallOpts, arg0, arg1, arg2, arg3)
+}
+
+// OnERC721Received is a free data retrieval call binding the contract method 0x150b7a02.
+//
+// Solidity: function onERC721Received(address , address , uint256 , bytes ) pure returns(bytes4)
+func (_SimpleWallet *SimpleWalletCallerSession) OnERC721Received(arg0 common.Address, arg1 common.Address, arg2 *big.Int, arg3 []byte) ([4]byte, error) {
+	return _SimpleWallet.Contract.OnERC721Received(&_SimpleWallet.CallOpts, arg0, arg1, arg2, arg3)
+}
+
+// Owner is a free data retrieval call binding the contract method 0x8da5cb5b.
+//
+// Solidity: function owner() view returns(address)
+func (_SimpleWallet *SimpleWalletCaller) Owner(opts *bind.CallOpts) (common.Address, error) {
+	var out []interface{}
+	err := _SimpleWallet.contract.Call(opts, &out, "owner")
+
+	if err != nil {
+		return *new(common.Address), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new(common.Address)).(*common.Address)
+
+	return out0, err
+
+}
+
+// Owner is a free data retrieval call binding the contract method 0x8da5cb5b.
+//
+// Solidity: function owner() view returns(address)
+func (_SimpleWallet *SimpleWalletSession) Owner() (common.Address, error) {
+	return _SimpleWallet.Contract.Owner(&_SimpleWallet.CallOpts)
+}
+
+// Owner is a free data retrieval call binding the contract method 0x8da5cb5b.
+//
+// Solidity: function owner() view returns(address)
+func (_SimpleWallet *SimpleWalletCallerSession) Owner() (common.Address, error) {
+	return _SimpleWallet.Contract.Owner(&_SimpleWallet.CallOpts)
+}
+
+// SupportsInterface is a free data retrieval call binding the contract method 0x01ffc9a7.
+//
+// Solidity: function supportsInterface(bytes4 interfaceId) view returns(bool)
+func (_SimpleWallet *SimpleWalletCaller) SupportsInterface(opts *bind.CallOpts, interfaceId [4]byte) (bool, error) {
+	var out []interface{}
+	err := _SimpleWallet.contract.Call(opts, &out, "supportsInterface", interfaceId)
+
+	if err != nil {
+		return *new(bool), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new(bool)).(*bool)
+
+	return out0, err
+
+}
+
+// SupportsInterface is a free data retrieval call binding the contract method 0x01ffc9a7.
+//
+// Solidity: function supportsInterface(bytes4 interfaceId) view returns(bool)
+func (_SimpleWallet *SimpleWalletSession) SupportsInterface(interfaceId [4]byte) (bool, error) {
+	return _SimpleWallet.Contract.SupportsInterface(&_SimpleWallet.CallOpts, interfaceId)
+}
+
+// SupportsInterface is a free data retrieval call binding the contract method 0x01ffc9a7.
+//
+// Solidity: function supportsInterface(bytes4 interfaceId) view returns(bool)
+func (_SimpleWallet *SimpleWalletCallerSession) SupportsInterface(interfaceId [4]byte) (bool, error) {
+	return _SimpleWallet.Contract.SupportsInterface(&_SimpleWallet.CallOpts, interfaceId)
+}
+
+// TokensReceived is a free data retrieval call binding the contract method 0x0023de29.
+//
+// Solidity: function tokensReceived(address , address , address , uint256 , bytes , bytes ) pure returns()
+func (_SimpleWallet *SimpleWalletCaller) TokensReceived(opts *bind.CallOpts, arg0 common.Address, arg1 common.Address, arg2 common.Address, arg3 *big.Int, arg4 []byte, arg5 []byte) error {
+	var out []interface{}
+	err := _SimpleWallet.contract.Call(opts, &out, "tokensReceived", arg0, arg1, arg2, arg3, arg4, arg5)
+
+	if err != nil {
+		return err
+	}
+
+	return err
+
+}
+
+// TokensReceived is a free data retrieval call binding the contract method 0x0023de29.
+//
+// Solidity: function tokensReceived(address , address , address , uint256 , bytes , bytes ) pure returns()
+func (_SimpleWallet *SimpleWalletSession) TokensReceived(arg0 common.Address, arg1 common.Address, arg2 common.Address, arg3 *big.Int, arg4 []byte, arg5 []byte) error {
+	return _SimpleWallet.Contract.TokensReceived(&_SimpleWallet.CallOpts, arg0, arg1, arg2, arg3, arg4, arg5)
+}
+
+// TokensReceived is a free data retrieval call binding the contract method 0x0023de29.
+//
+// Solidity: function tokensReceived(address , address , address , uint256 , bytes , bytes ) pure returns()
+func (_SimpleWallet *SimpleWalletCallerSession) TokensReceived(arg0 common.Address, arg1 common.Address, arg2 common.Address, arg3 *big.Int, arg4 []byte, arg5 []byte) error {
+	return _SimpleWallet.Contract.TokensReceived(&_SimpleWallet.CallOpts, arg0, arg1, arg2, arg3, arg4, arg5)
+}
+
+// AddDeposit is a paid mutator transaction binding the contract method 0x4a58db19.
+//
+// Solidity: function addDeposit() payable returns()
+func (_SimpleWallet *SimpleWalletTransactor) AddDeposit(opts *bind.TransactOpts) (*types.Transaction, error) {
+	return _SimpleWallet.contract.Transact(opts, "addDeposit")
+}
+
+// AddDeposit is a paid mutator transaction binding the contract method 0x4a58db19.
+//
+// Solidity: function addDeposit() payable returns()
+func (_SimpleWallet *SimpleWalletSession) AddDeposit() (*types.Transaction, error) {
+	return _SimpleWallet.Contract.AddDeposit(&_SimpleWallet.TransactOpts)
+}
+
+// AddDeposit is a paid mutator transaction binding the contract method 0x4a58db19.
+//
+// Solidity: function addDeposit() payable returns()
+func (_SimpleWallet *SimpleWalletTransactorSession) AddDeposit() (*types.Transaction, error) {
+	return _SimpleWallet.Contract.AddDeposit(&_SimpleWallet.TransactOpts)
+}
+
+// ChangeOwner is a paid mutator transaction binding the contract method 0xa6f9dae1.
+//
+// Solidity: function changeOwner(address newOwner) returns()
+func (_SimpleWallet *SimpleWalletTransactor) ChangeOwner(opts *bind.TransactOpts, newOwner common.Address) (*types.Transaction, error) {
+	return _SimpleWallet.contract.Transact(opts, "changeOwner", newOwner)
+}
+
+// ChangeOwner is a paid mutator transaction binding the contract method 0xa6f9dae1.
+//
+// Solidity: function changeOwner(address newOwner) returns()
+func (_SimpleWallet *SimpleWalletSession) ChangeOwner(newOwner common.Address) (*types.Transaction, error) {
+	return _SimpleWallet.Contract.ChangeOwner(&_SimpleWallet.TransactOpts, newOwner)
+}
+
+// ChangeOwner is a paid mutator transaction binding the contract method 0xa6f9dae1.
+//
+// Solidity: function changeOwner(address newOwner) returns()
+func (_SimpleWallet *SimpleWalletTransactorSession) ChangeOwner(newOwner common.Address) (*types.Transaction, error) {
+	return _SimpleWallet.Contract.ChangeOwner(&_SimpleWallet.TransactOpts, newOwner)
+}
+
+// ChangePaymaster is a paid mutator transaction binding the contract method 0xbdafd324.
+//
+// Solidity: function changePaymaster(address newPaymaster) returns()
+func (_SimpleWallet *SimpleWalletTransactor) ChangePaymaster(opts *bind.TransactOpts, newPaymaster common.Address) (*types.Transaction, error) {
+	return _SimpleWallet.contract.Transact(opts, "changePaymaster", newPaymaster)
+}
+
+// ChangePaymaster is a paid mutator transaction binding the contract method 0xbdafd324.
+//
+// Solidity: function changePaymaster(address newPaymaster) returns()
+func (_SimpleWallet *SimpleWalletSession) ChangePaymaster(newPaymaster common.Address) (*types.Transaction, error) {
+	return _SimpleWallet.Contract.ChangePaymaster(&_SimpleWallet.TransactOpts, newPaymaster)
+}
+
+// ChangePaymaster is a paid mutator transaction binding the contract method 0xbdafd324.
+//
+// Solidity: function changePaymaster(address newPaymaster) returns()
+func (_SimpleWallet *SimpleWalletTransactorSession) ChangePaymaster(newPaymaster common.Address) (*types.Transaction, error) {
+	return _SimpleWallet.Contract.ChangePaymaster(&_SimpleWallet.TransactOpts, newPaymaster)
+}
+
+// Exec is a paid mutator transaction binding the contract method 0x0565bb67.
+//
+// Solidity: function exec(address dest, uint256 value, bytes func) returns()
+func (_SimpleWallet *SimpleWalletTransactor) Exec(opts *bind.TransactOpts, dest common.Address, value *big.Int, arg2 []byte) (*types.Transaction, error) {
+	return _SimpleWallet.contract.Transact(opts, "exec", dest, value, arg2)
+}
+
+// Exec is a paid mutator transaction binding the contract method 0x0565bb67.
+//
+// Solidity: function exec(address dest, uint256 value, bytes func) returns()
+func (_SimpleWallet *SimpleWalletSession) Exec(dest common.Address, value *big.Int, arg2 []byte) (*types.Transaction, error) {
+	return _SimpleWallet.Contract.Exec(&_SimpleWallet.TransactOpts, dest, value, arg2)
+}
+
+// Exec is a paid mutator transaction binding the contract method 0x0565bb67.
+//
+// Solidity: function exec(address dest, uint256 value, bytes func) returns()
+func (_SimpleWallet *SimpleWalletTransactorSession) Exec(dest common.Address, value *big.Int, arg2 []byte) (*types.Transaction, error) {
+	return _SimpleWallet.Contract.Exec(&_SimpleWallet.TransactOpts, dest, value, arg2)
+}
+
+// ExecBatch is a paid mutator transaction binding the contract method 0xd0cb75fa.
+//
+// Solidity: function execBatch(address[] dest, bytes[] func) returns()
+func (_SimpleWallet *SimpleWalletTransactor) ExecBatch(opts *bind.TransactOpts, dest []common.Address, arg1 [][]byte) (*types.Transaction, error) {
+	return _SimpleWallet.contract.Transact(opts, "execBatch", dest, arg1)
+}
+
+// ExecBatch is a paid mutator transaction binding the contract method 0xd0cb75fa.
+//
+// Solidity: function execBatch(address[] dest, bytes[] func) returns()
+func (_SimpleWallet *SimpleWalletSession) ExecBatch(dest []common.Address, arg1 [][]byte) (*types.Transaction, error) {
+	return _SimpleWallet.Contract.ExecBatch(&_SimpleWallet.TransactOpts, dest, arg1)
+}
+
+// ExecBatch is a paid mutator transaction binding the contract method 0xd0cb75fa.
+//
+// Solidity: function execBatch(address[] dest, bytes[] func) returns()
+func (_SimpleWallet *SimpleWalletTransactorSession) ExecBatch(dest []common.Address, arg1 [][]byte) (*types.Transaction, error) {
+	return _SimpleWallet.Contract.ExecBatch(&_SimpleWallet.TransactOpts, dest, arg1)
+}
+
+// ExecFromEntryPoint is a paid mutator transaction binding the contract method 0x80c5c7d0.
+//
+// Solidity: function execFromEntryPoint(address dest, uint256 value, bytes func) returns()
+func (_SimpleWallet *SimpleWalletTransactor) ExecFromEntryPoint(opts *bind.TransactOpts, dest common.Address, value *big.Int, arg2 []byte) (*types.Transaction, error) {
+	return _SimpleWallet.contract.Transact(opts, "execFromEntryPoint", dest, value, arg2)
+}
+
+// ExecFromEntryPoint is a paid mutator transaction binding the contract method 0x80c5c7d0.
+//
+// Solidity: function execFromEntryPoint(address dest, uint256 value, bytes func) returns()
+func (_SimpleWallet *SimpleWalletSession) ExecFromEntryPoint(dest common.Address, value *big.Int, arg2 []byte) (*types.Transaction, error) {
+	return _SimpleWallet.Contract.ExecFromEntryPoint(&_SimpleWallet.TransactOpts, dest, value, arg2)
+}
+
+// ExecFromEntryPoint is a paid mutator transaction binding the contract method 0x80c5c7d0.
+//
+// Solidity: function execFromEntryPoint(address dest, uint256 value, bytes func) returns()
+func (_SimpleWallet *SimpleWalletTransactorSession) ExecFromEntryPoint(dest common.Address, value *big.Int, arg2 []byte) (*types.Transaction, error) {
+	return _SimpleWallet.Contract.ExecFromEntryPoint(&_SimpleWallet.TransactOpts, dest, value, arg2)
+}
+
+// Transfer is a paid mutator transaction binding the contract method 0xa9059cbb.
+//
+// Solidity: function transfer(address dest, uint256 amount) returns()
+func (_SimpleWallet *SimpleWalletTransactor) Transfer(opts *bind.TransactOpts, dest common.Address, amount *big.Int) (*types.Transaction, error) {
+	return _SimpleWallet.contract.Transact(opts, "transfer", dest, amount)
+}
+
+// Transfer is a paid mutator transaction binding the contract method 0xa9059cbb.
+//
+// Solidity: function transfer(address dest, uint256 amount) returns()
+func (_SimpleWallet *SimpleWalletSession) Transfer(dest common.Address, amount *big.Int) (*types.Transaction, error) {
+	return _SimpleWallet.Contract.Transfer(&_SimpleWallet.TransactOpts, dest, amount)
+}
+
+// Transfer is a paid mutator transaction binding the contract method 0xa9059cbb.
+//
+// Solidity: function transfer(address dest, uint256 amount) returns()
+func (_SimpleWallet *SimpleWalletTransactorSession) Transfer(dest common.Address, amount *big.Int) (*types.Transaction, error) {
+	return _SimpleWallet.Contract.Transfer(&_SimpleWallet.TransactOpts, dest, amount)
+}
+
+// UpdateEntryPoint is a paid mutator transaction binding the contract method 0x1b71bb6e.
+//
+// Solidity: function updateEntryPoint(address newEntryPoint) returns()
+func (_SimpleWallet *SimpleWalletTransactor) UpdateEntryPoint(opts *bind.TransactOpts, newEntryPoint common.Address) (*types.Transaction, error) {
+	return _SimpleWallet.contract.Transact(opts, "updateEntryPoint", newEntryPoint)
+}
+
+// UpdateEntryPoint is a paid mutator transaction binding the contract method 0x1b71bb6e.
+//
+// Solidity: function updateEntryPoint(address newEntryPoint) returns()
+func (_SimpleWallet *SimpleWalletSession) UpdateEntryPoint(newEntryPoint common.Address) (*types.Transaction, error) {
+	return _SimpleWallet.Contract.UpdateEntryPoint(&_SimpleWallet.TransactOpts, newEntryPoint)
+}
+
+// UpdateEntryPoint is a paid mutator transaction binding the contract method 0x1b71bb6e.
+//
+// Solidity: function updateEntryPoint(address newEntryPoint) returns()
+func (_SimpleWallet *SimpleWalletTransactorSession) UpdateEntryPoint(newEntryPoint common.Address) (*types.Transaction, error) {
+	return _SimpleWallet.Contract.UpdateEntryPoint(&_SimpleWallet.TransactOpts, newEntryPoint)
+}
+
+// WithdrawDepositTo is a paid mutator transaction binding the contract method 0x4d44560d.
+//
+// Solidity: function withdrawDepositTo(address withdrawAddress, uint256 amount) returns()
+func (_SimpleWallet *SimpleWalletTransactor) WithdrawDepositTo(opts *bind.TransactOpts, withdrawAddress common.Address, amount *big.Int) (*types.Transaction, error) {
+	return _SimpleWallet.contract.Transact(opts, "withdrawDepositTo", withdrawAddress, amount)
+}
+
+// WithdrawDepositTo is a paid mutator transaction binding the contract method 0x4d44560d.
+//
+// Solidity: function withdrawDepositTo(address withdrawAddress, uint256 amount) returns()
+func (_SimpleWallet *SimpleWalletSession) WithdrawDepositTo(withdrawAddress common.Address, amount *big.Int) (*types.Transaction, error) {
+	return _SimpleWallet.Contract.WithdrawDepositTo(&_SimpleWallet.TransactOpts, withdrawAddress, amount)
+}
+
+// WithdrawDepositTo is a paid mutator transaction binding the contract method 0x4d44560d.
+//
+// Solidity: function withdrawDepositTo(address withdrawAddress, uint256 amount) returns()
+func (_SimpleWallet *SimpleWalletTransactorSession) WithdrawDepositTo(withdrawAddress common.Address, amount *big.Int) (*types.Transaction, error) {
+	return _SimpleWallet.Contract.WithdrawDepositTo(&_SimpleWallet.TransactOpts, withdrawAddress, amount)
+}
+
+// Receive is a paid mutator transaction binding the contract receive function.
+//
+// Solidity: receive() payable returns()
+func (_SimpleWallet *SimpleWalletTransactor) Receive(opts *bind.TransactOpts) (*types.Transaction, error) {
+	return _SimpleWallet.contract.RawTransact(opts, nil) // calldata is disallowed for receive function
+}
+
+// Receive is a paid mutator transaction binding the contract receive function.
+//
+// Solidity: receive() payable returns()
+func (_SimpleWallet *SimpleWalletSession) Receive() (*types.Transaction, error) {
+	return _SimpleWallet.Contract.Receive(&_SimpleWallet.TransactOpts)
+}
+
+// Receive is a paid mutator transaction binding the contract receive function.
+//
+// Solidity: receive() payable returns()
+func (_SimpleWallet *SimpleWalletTransactorSession) Receive() (*types.Transaction, error) {
+	return _SimpleWallet.Contract.Receive(&_SimpleWallet.TransactOpts)
+}
+
+// SimpleWalletEntryPointChangedIterator is returned from FilterEntryPointChanged and is used to iterate over the raw logs and unpacked data for EntryPointChanged events raised by the SimpleWallet contract.
+type SimpleWalletEntryPointChangedIterator struct {
+	Event *SimpleWalletEntryPointChanged // Event containing the contract specifics and raw log
+
+	contract *bind.BoundContract // Generic contract to use for unpacking event data
+	event    string              // Event name to use for unpacking event data
+
+	logs chan types.Log        // Log channel receiving the found contract events
+	sub  ethereum.Subscription // Subscription for errors, completion and termination
+	done bool                  // Whether the subscription completed delivering logs
+	fail error                 // Occurred error to stop iteration
+}
+
+// Next advances the iterator to the subsequent event, returning whether there
+// are any more events found. In case of a retrieval or parsing error, false is
+// returned and Error() can be queried for the exact failure.
+func (it *SimpleWalletEntryPointChangedIterator) Next() bool {
+	// If the iterator failed, stop iterating
+	if it.fail != nil {
+		return false
+	}
+	// If the iterator completed, deliver directly whatever's available
+	if it.done {
+		select {
+		case log := <-it.logs:
+			it.Event = new(SimpleWalletEntryPointChanged)
+			if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+				it.fail = err
+				return false
+			}
+			it.Event.Raw = log
+			return true
+
+		default:
+			return false
+		}
+	}
+	// Iterator still in progress, wait for either a data or an error event
+	select {
+	case log := <-it.logs:
+		it.Event = new(SimpleWalletEntryPointChanged)
+		if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+			it.fail = err
+			return false
+		}
+		it.Event.Raw = log
+		return true
+
+	case err := <-it.sub.Err():
+		it.done = true
+		it.fail = err
+		return it.Next()
+	}
+}
+
+// Error returns any retrieval or parsing error occurred during filtering.
+func (it *SimpleWalletEntryPointChangedIterator) Error() error {
+	return it.fail
+}
+
+// Close terminates the iteration process, releasing any pending underlying
+// resources.
+func (it *SimpleWalletEntryPointChangedIterator) Close() error {
+	it.sub.Unsubscribe()
+	return nil
+}
+
+// SimpleWalletEntryPointChanged represents a EntryPointChanged event raised by the SimpleWallet contract.
+type SimpleWalletEntryPointChanged struct {
+	OldEntryPoint common.Address
+	NewEntryPoint common.Address
+	Raw           types.Log // Blockchain specific contextual infos
+}
+
+// FilterEntryPointChanged is a free log retrieval operation binding the contract event 0x450909c1478d09248269d4ad4fa8cba61ca3f50faed58c7aedefa51c7f62b83a.
+//
+// Solidity: event EntryPointChanged(address indexed oldEntryPoint, address indexed newEntryPoint)
+func (_SimpleWallet *SimpleWalletFilterer) FilterEntryPointChanged(opts *bind.FilterOpts, oldEntryPoint []common.Address, newEntryPoint []common.Address) (*SimpleWalletEntryPointChangedIterator, error) {
+
+	var oldEntryPointRule []interface{}
+	for _, oldEntryPointItem := range oldEntryPoint {
+		oldEntryPointRule = append(oldEntryPointRule, oldEntryPointItem)
+	}
+	var newEntryPointRule []interface{}
+	for _, newEntryPointItem := range newEntryPoint {
+		newEntryPointRule = append(newEntryPointRule, newEntryPointItem)
+	}
+
+	logs, sub, err := _SimpleWallet.contract.FilterLogs(opts, "EntryPointChanged", oldEntryPointRule, newEntryPointRule)
+	if err != nil {
+		return nil, err
+	}
+	return &SimpleWalletEntryPointChangedIterator{contract: _SimpleWallet.contract, event: "EntryPointChanged", logs: logs, sub: sub}, nil
+}
+
+// WatchEntryPointChanged is a free log subscription operation binding the contract event 0x450909c1478d09248269d4ad4fa8cba61ca3f50faed58c7aedefa51c7f62b83a.
+//
+// Solidity: event EntryPointChanged(address indexed oldEntryPoint, address indexed newEntryPoint)
+func (_SimpleWallet *SimpleWalletFilterer) WatchEntryPointChanged(opts *bind.WatchOpts, sink chan<- *SimpleWalletEntryPointChanged, oldEntryPoint []common.Address, newEntryPoint []common.Address) (event.Subscription, error) {
+
+	var oldEntryPointRule []interface{}
+	for _, oldEntryPointItem := range oldEntryPoint {
+		oldEntryPointRule = append(oldEntryPointRule, oldEntryPointItem)
+	}
+	var newEntryPointRule []interface{}
+	for _, newEntryPointItem := range newEntryPoint {
+		newEntryPointRule = append(newEntryPointRule, newEntryPointItem)
+	}
+
+	logs, sub, err := _SimpleWallet.contract.WatchLogs(opts, "EntryPointChanged", oldEntryPointRule, newEntryPointRule)
+	if err != nil {
+		return nil, err
+	}
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		defer sub.Unsubscribe()
+		for {
+			select {
+			case log := <-logs:
+				// New log arrived, parse the event and forward to the user
+				event := new(SimpleWalletEntryPointChanged)
+				if err := _SimpleWallet.contract.UnpackLog(event, "EntryPointChanged", log); err != nil {
+					return err
+				}
+				event.Raw = log
+
+				select {
+				case sink <- event:
+				case err := <-sub.Err():
+					return err
+				case <-quit:
+					return nil
+				}
+			case err := <-sub.Err():
+				return err
+			case <-quit:
+				return nil
+			}
+		}
+	}), nil
+}
+
+// ParseEntryPointChanged is a log parse operation binding the contract event 0x450909c1478d09248269d4ad4fa8cba61ca3f50faed58c7aedefa51c7f62b83a.
+//
+// Solidity: event EntryPointChanged(address indexed oldEntryPoint, address indexed newEntryPoint)
+func (_SimpleWallet *SimpleWalletFilterer) ParseEntryPointChanged(log types.Log) (*SimpleWalletEntryPointChanged, error) {
+	event := new(SimpleWalletEntryPointChanged)
+	if err := _SimpleWallet.contract.UnpackLog(event, "EntryPointChanged", log); err != nil {
+		return nil, err
+	}
+	event.Raw = log
+	return event, nil
+}
+
+// SimpleWalletOwnerChangedIterator is returned from FilterOwnerChanged and is used to iterate over the raw logs and unpacked data for OwnerChanged events raised by the SimpleWallet contract.
+type SimpleWalletOwnerChangedIterator struct {
+	Event *SimpleWalletOwnerChanged // Event containing the contract specifics and raw log
+
+	contract *bind.BoundContract // Generic contract to use for unpacking event data
+	event    string              // Event name to use for unpacking event data
+
+	logs chan types.Log        // Log channel receiving the found contract events
+	sub  ethereum.Subscription // Subscription for errors, completion and termination
+	done bool                  // Whether the subscription completed delivering logs
+	fail error                 // Occurred error to stop iteration
+}
+
+// Next advances the iterator to the subsequent event, returning whether there
+// are any more events found. In case of a retrieval or parsing error, false is
+// returned and Error() can be queried for the exact failure.
+func (it *SimpleWalletOwnerChangedIterator) Next() bool {
+	// If the iterator failed, stop iterating
+	if it.fail != nil {
+		return false
+	}
+	// If the iterator completed, deliver directly whatever's available
+	if it.done {
+		select {
+		case log := <-it.logs:
+			it.Event = new(SimpleWalletOwnerChanged)
+			if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+				it.fail = err
+				return false
+			}
+			it.Event.Raw = log
+			return true
+
+		default:
+			return false
+		}
+	}
+	// Iterator still in progress, wait for either a data or an error event
+	select {
+	case log := <-it.logs:
+		it.Event = new(SimpleWalletOwnerChanged)
+		if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+			it.fail = err
+			return false
+		}
+		it.Event.Raw = log
+		return true
+
+	case err := <-it.sub.Err():
+		it.done = true
+		it.fail = err
+		return it.Next()
+	}
+}
+
+// Error returns any retrieval or parsing error occurred during filtering.
+func (it *SimpleWalletOwnerChangedIterator) Error() error {
+	return it.fail
+}
+
+// Close terminates the iteration process, releasing any pending underlying
+// resources.
+func (it *SimpleWalletOwnerChangedIterator) Close() error {
+	it.sub.Unsubscribe()
+	return nil
+}
+
+// SimpleWalletOwnerChanged represents a OwnerChanged event raised by the SimpleWallet contract.
+type SimpleWalletOwnerChanged struct {
+	OldOwner common.Address
+	NewOwner common.Address
+	Raw      types.Log // Blockchain specific contextual infos
+}
+
+// FilterOwnerChanged is a free log retrieval operation binding the contract event 0xb532073b38c83145e3e5135377a08bf9aab55bc0fd7c1179cd4fb995d2a5159c.
+//
+// Solidity: event OwnerChanged(address indexed oldOwner, address indexed newOwner)
+func (_SimpleWallet *SimpleWalletFilterer) FilterOwnerChanged(opts *bind.FilterOpts, oldOwner []common.Address, newOwner []common.Address) (*SimpleWalletOwnerChangedIterator, error) {
+
+	var oldOwnerRule []interface{}
+	for _, oldOwnerItem := range oldOwner {
+		oldOwnerRule = append(oldOwnerRule, oldOwnerItem)
+	}
+	var newOwnerRule []interface{}
+	for _, newOwnerItem := range newOwner {
+		newOwnerRule = append(newOwnerRule, newOwnerItem)
+	}
+
+	logs, sub, err := _SimpleWallet.contract.FilterLogs(opts, "OwnerChanged", oldOwnerRule, newOwnerRule)
+	if err != nil {
+		return nil, err
+	}
+	return &SimpleWalletOwnerChangedIterator{contract: _SimpleWallet.contract, event: "OwnerChanged", logs: logs, sub: sub}, nil
+}
+
+// WatchOwnerChanged is a free log subscription operation binding the contract event 0xb532073b38c83145e3e5135377a08bf9aab55bc0fd7c1179cd4fb995d2a5159c.
+//
+// Solidity: event OwnerChanged(address indexed oldOwner, address indexed newOwner)
+func (_SimpleWallet *SimpleWalletFilterer) WatchOwnerChanged(opts *bind.WatchOpts, sink chan<- *SimpleWalletOwnerChanged, oldOwner []common.Address, newOwner []common.Address) (event.Subscription, error) {
+
+	var oldOwnerRule []interface{}
+	for _, oldOwnerItem := range oldOwner {
+		oldOwnerRule = append(oldOwnerRule, oldOwnerItem)
+	}
+	var newOwnerRule []interface{}
+	for _, newOwnerItem := range newOwner {
+		newOwnerRule = append(newOwnerRule, newOwnerItem)
+	}
+
+	logs, sub, err := _SimpleWallet.contract.WatchLogs(opts, "OwnerChanged", oldOwnerRule, newOwnerRule)
+	if err != nil {
+		return nil, err
+	}
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		defer sub.Unsubscribe()
+		for {
+			select {
+			case log := <-logs:
+				// New log arrived, parse the event and forward to the user
+				event := new(SimpleWalletOwnerChanged)
+				if err := _SimpleWallet.contract.UnpackLog(event, "OwnerChanged", log); err != nil {
+					return err
+				}
+				event.Raw = log
+
+				select {
+				case sink <- event:
+				case err := <-sub.Err():
+					return err
+				case <-quit:
+					return nil
+				}
+			case err := <-sub.Err():
+				return err
+			case <-quit:
+				return nil
+			}
+		}
+	}), nil
+}
+
+// ParseOwnerChanged is a log parse operation binding the contract event 0xb532073b38c83145e3e5135377a08bf9aab55bc0fd7c1179cd4fb995d2a5159c.
+//
+// Solidity: event OwnerChanged(address indexed oldOwner, address indexed newOwner)
+func (_SimpleWallet *SimpleWalletFilterer) ParseOwnerChanged(log types.Log) (*SimpleWalletOwnerChanged, error) {
+	event := new(SimpleWalletOwnerChanged)
+	if err := _SimpleWallet.contract.UnpackLog(event, "OwnerChanged", log); err != nil {
+		return nil, err
+	}
+	event.Raw = log
+	return event, nil
+}
+
+// SimpleWalletPaymasterChangedIterator is returned from FilterPaymasterChanged and is used to iterate over the raw logs and unpacked data for PaymasterChanged events raised by the SimpleWallet contract.
+type SimpleWalletPaymasterChangedIterator struct {
+	Event *SimpleWalletPaymasterChanged // Event containing the contract specifics and raw log
+
+	contract *bind.BoundContract // Generic contract to use for unpacking event data
+	event    string              // Event name to use for unpacking event data
+
+	logs chan types.Log        // Log channel receiving the found contract events
+	sub  ethereum.Subscription // Subscription for errors, completion and termination
+	done bool                  // Whether the subscription completed delivering logs
+	fail error                 // Occurred error to stop iteration
+}
+
+// Next advances the iterator to the subsequent event, returning whether there
+// are any more events found. In case of a retrieval or parsing error, false is
+// returned and Error() can be queried for the exact failure.
+func (it *SimpleWalletPaymasterChangedIterator) Next() bool {
+	// If the iterator failed, stop iterating
+	if it.fail != nil {
+		return false
+	}
+	// If the iterator completed, deliver directly whatever's available
+	if it.done {
+		select {
+		case log := <-it.logs:
+			it.Event = new(SimpleWalletPaymasterChanged)
+			if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+				it.fail = err
+				return false
+			}
+			it.Event.Raw = log
+			return true
+
+		default:
+			return false
+		}
+	}
+	// Iterator still in progress, wait for either a data or an error event
+	select {
+	case log := <-it.logs:
+		it.Event = new(SimpleWalletPaymasterChanged)
+		if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+			it.fail = err
+			return false
+		}
+		it.Event.Raw = log
+		return true
+
+	case err := <-it.sub.Err():
+		it.done = true
+		it.fail = err
+		return it.Next()
+	}
+}
+
+// Error returns any retrieval or parsing error occurred during filtering.
+func (it *SimpleWalletPaymasterChangedIterator) Error() error {
+	return it.fail
+}
+
+// Close terminates the iteration process, releasing any pending underlying
+// resources.
+func (it *SimpleWalletPaymasterChangedIterator) Close() error {
+	it.sub.Unsubscribe()
+	return nil
+}
+
+// SimpleWalletPaymasterChanged represents a PaymasterChanged event raised by the SimpleWallet contract.
+type SimpleWalletPaymasterChanged struct {
+	OldPaymaster common.Address
+	NewPaymaster common.Address
+	Raw          types.Log // Blockchain specific contextual infos
+}
+
+// FilterPaymasterChanged is a free log retrieval operation binding the contract event 0xd45bf891e206e35d5a4aa835adaabaf67518307c30d328fd23cd9dd0669128b3.
+//
+// Solidity: event PaymasterChanged(address indexed oldPaymaster, address indexed newPaymaster)
+func (_SimpleWallet *SimpleWalletFilterer) FilterPaymasterChanged(opts *bind.FilterOpts, oldPaymaster []common.Address, newPaymaster []common.Address) (*SimpleWalletPaymasterChangedIterator, error) {
+
+	var oldPaymasterRule []interface{}
+	for _, oldPaymasterItem := range oldPaymaster {
+		oldPaymasterRule = append(oldPaymasterRule, oldPaymasterItem)
+	}
+	var newPaymasterRule []interface{}
+	for _, newPaymasterItem := range newPaymaster {
+		newPaymasterRule = append(newPaymasterRule, newPaymasterItem)
+	}
+
+	logs, sub, err := _SimpleWallet.contract.FilterLogs(opts, "PaymasterChanged", oldPaymasterRule, newPaymasterRule)
+	if err != nil {
+		return nil, err
+	}
+	return &SimpleWalletPaymasterChangedIterator{contract: _SimpleWallet.contract, event: "PaymasterChanged", logs: logs, sub: sub}, nil
+}
+
+// WatchPaymasterChanged is a free log subscription operation binding the contract event 0xd45bf891e206e35d5a4aa835adaabaf67518307c30d328fd23cd9dd0669128b3.
+//
+// Solidity: event PaymasterChanged(address indexed oldPaymaster, address indexed newPaymaster)
+func (_SimpleWallet *SimpleWalletFilterer) WatchPaymasterChanged(opts *bind.WatchOpts, sink chan<- *SimpleWalletPaymasterChanged, oldPaymaster []common.Address, newPaymaster []common.Address) (event.Subscription, error) {
+
+	var oldPaymasterRule []interface{}
+	for _, oldPaymasterItem := range oldPaymaster {
+		oldPaymasterRule = append(oldPaymasterRule, oldPaymasterItem)
+	}
+	var newPaymasterRule []interface{}
+	for _, newPaymasterItem := range newPaymaster {
+		newPaymasterRule = append(newPaymasterRule, newPaymasterItem)
+	}
+
+	logs, sub, err := _SimpleWallet.contract.WatchLogs(opts, "PaymasterChanged", oldPaymasterRule, newPaymasterRule)
+	if err != nil {
+		return nil, err
+	}
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		defer sub.Unsubscribe()
+		for {
+			select {
+			case log := <-logs:
+				// New log arrived, parse the event and forward to the user
+				event := new(SimpleWalletPaymasterChanged)
+				if err := _SimpleWallet.contract.UnpackLog(event, "PaymasterChanged", log); err != nil {
+					return err
+				}
+				event.Raw = log
+
+				select {
+				case sink <- event:
+				case err := <-sub.Err():
+					return err
+				case <-quit:
+					return nil
+				}
+			case err := <-sub.Err():
+				return err
+			case <-quit:
+				return nil
+			}
+		}
+	}), nil
+}
+
+// ParsePaymasterChanged is a log parse operation binding the contract event 0xd45bf891e206e35d5a4aa835adaabaf67518307c30d328fd23cd9dd0669128b3.
+//
+// Solidity: event PaymasterChanged(address indexed oldPaymaster, address indexed newPaymaster)
+func (_SimpleWallet *SimpleWalletFilterer) ParsePaymasterChanged(log types.Log) (*SimpleWalletPaymasterChanged, error) {
+	event := new(SimpleWalletPaymasterChanged)
+	if err := _SimpleWallet.contract.UnpackLog(event, "PaymasterChanged", log); err != nil {
+		return nil, err
+	}
+	event.Raw = log
+	return event, nil
+}
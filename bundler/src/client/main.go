@@ -0,0 +1,123 @@
+package client
+
+import (
+	"context"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/xerrors"
+
+	"bundler/abi"
+	"bundler/controller"
+)
+
+var (
+	l = logrus.WithField("module", "client")
+)
+
+// Client builds, signs and submits user operations of SimpleWallets through a bundler.
+type Client struct {
+	// JSON-RPC endpoint of the bundler, e.g. `http://localhost:8080/rpc`.
+	bundler *rpc.Client
+	// Chain node, used to read wallet nonces and fees.
+	node *ethclient.Client
+
+	ChainID    *big.Int
+	EntryPoint common.Address
+}
+
+// New creates a client sending operations to entryPoint on chain chainID.
+func New(bundler *rpc.Client, node *ethclient.Client, chainID *big.Int, entryPoint common.Address) *Client {
+	return &Client{bundler: bundler, node: node, ChainID: chainID, EntryPoint: entryPoint}
+}
+
+// Dial connects to a bundler and a node of the same chain.
+// Operations are sent to the default entry point of the bundler.
+func Dial(ctx context.Context, bundlerURL string, nodeURL string) (*Client, error) {
+	c, err := DialBundler(ctx, bundlerURL)
+	if err != nil {
+		return nil, err
+	}
+	node, err := ethclient.DialContext(ctx, nodeURL)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to connect to node: %w", err)
+	}
+	nodeChainID, err := node.ChainID(ctx)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to get chain ID of node: %w", err)
+	}
+	if nodeChainID.Cmp(c.ChainID) != 0 {
+		return nil, xerrors.Errorf("bundler serves chain %s, but node is on chain %s", c.ChainID.String(), nodeChainID.String())
+	}
+	c.node = node
+	return c, nil
+}
+
+// DialBundler connects to a bundler only. The client can sign and send operations,
+// but not fill nonce, fees or gas of them.
+func DialBundler(ctx context.Context, bundlerURL string) (*Client, error) {
+	bundler, err := rpc.DialContext(ctx, bundlerURL)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to connect to bundler: %w", err)
+	}
+
+	chainID := hexutil.Big{}
+	if err := bundler.CallContext(ctx, &chainID, "eth_chainId"); err != nil {
+		return nil, xerrors.Errorf("failed to get chain ID of bundler: %w", err)
+	}
+	entryPoints := []common.Address{}
+	if err := bundler.CallContext(ctx, &entryPoints, "eth_supportedEntryPoints"); err != nil {
+		return nil, xerrors.Errorf("failed to get entry points of bundler: %w", err)
+	}
+	if len(entryPoints) == 0 {
+		return nil, xerrors.New("bundler supports no entry point")
+	}
+
+	return New(bundler, nil, chainID.ToInt(), entryPoints[0]), nil
+}
+
+// Send submits a signed operation to the bundler, returning its request ID.
+func (c *Client) Send(ctx context.Context, op abi.UserOperation) (common.Hash, error) {
+	requestID := common.Hash{}
+	if err := c.bundler.CallContext(ctx, &requestID, "eth_sendUserOperation", controller.NewRPCUserOperation(op), c.EntryPoint); err != nil {
+		return common.Hash{}, xerrors.Errorf("failed to send user operation: %w", err)
+	}
+	return requestID, nil
+}
+
+// GetReceipt returns execution result of an operation, nil if it is not included yet.
+func (c *Client) GetReceipt(ctx context.Context, requestID common.Hash) (*controller.RPCUserOperationReceipt, error) {
+	var receipt *controller.RPCUserOperationReceipt
+	if err := c.bundler.CallContext(ctx, &receipt, "eth_getUserOperationReceipt", requestID); err != nil {
+		return nil, xerrors.Errorf("failed to get user operation receipt: %w", err)
+	}
+	return receipt, nil
+}
+
+// WaitForReceipt polls the bundler every interval until the operation is included, or ctx is done.
+func (c *Client) WaitForReceipt(ctx context.Context, requestID common.Hash, interval time.Duration) (*controller.RPCUserOperationReceipt, error) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		receipt, err := c.GetReceipt(ctx, requestID)
+		if err != nil {
+			return nil, err
+		}
+		if receipt != nil {
+			return receipt, nil
+		}
+		l.Debugf("User operation %s is not included yet", requestID.Hex())
+
+		select {
+		case <-ctx.Done():
+			return nil, xerrors.Errorf("user operation %s is not included: %w", requestID.Hex(), ctx.Err())
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,190 @@
+package client
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"math/big"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/stretchr/testify/require"
+
+	"bundler/abi"
+	"bundler/controller"
+	"bundler/eth"
+)
+
+var (
+	testEntryPoint = common.HexToAddress("0x8A42F70047a99298822dD1dbA34b454fc49913F2")
+	testWallet     = common.HexToAddress("0x7f477B448FA08E8801c7fe44546e6aEae9Daae19")
+)
+
+const testBlockGasLimit = 30000000
+
+// fakeServer serves both bundler and node methods used by Client.
+type fakeServer struct {
+	t   *testing.T
+	key *ecdsa.PrivateKey
+	// Operations received by `eth_sendUserOperation`.
+	sent []controller.RPCUserOperation
+	// `eth_getUserOperationReceipt` returns null until polled this many times.
+	pendingPolls int
+}
+
+func (s *fakeServer) ChainId() hexutil.Big {
+	return hexutil.Big(*big.NewInt(80001))
+}
+
+func (s *fakeServer) SupportedEntryPoints() []common.Address {
+	return []common.Address{testEntryPoint}
+}
+
+func (s *fakeServer) GetCode(address common.Address, block string) hexutil.Bytes {
+	return hexutil.Bytes{0x00}
+}
+
+// Call answers `nonce()` of the wallet.
+func (s *fakeServer) Call(args map[string]any, block string) hexutil.Bytes {
+	return common.LeftPadBytes([]byte{7}, 32)
+}
+
+func (s *fakeServer) GetBlockByNumber(number string, full bool) *types.Header {
+	return &types.Header{Number: big.NewInt(1), Difficulty: big.NewInt(0), GasLimit: testBlockGasLimit, BaseFee: big.NewInt(100)}
+}
+
+func (s *fakeServer) MaxPriorityFeePerGas() *hexutil.Big {
+	return (*hexutil.Big)(big.NewInt(2))
+}
+
+func (s *fakeServer) requireSigned(uo controller.RPCUserOperation) abi.UserOperation {
+	op, err := uo.ToABIStruct()
+	require.NoError(s.t, err)
+	requestID, err := eth.RequestID(op, testEntryPoint, big.NewInt(80001))
+	require.NoError(s.t, err)
+	signature := common.CopyBytes(op.Signature)
+	signature[64] -= 27
+	pub, err := crypto.SigToPub(accounts.TextHash(requestID.Bytes()), signature)
+	require.NoError(s.t, err)
+	require.Equal(s.t, crypto.PubkeyToAddress(s.key.PublicKey), crypto.PubkeyToAddress(*pub))
+	return op
+}
+
+func (s *fakeServer) EstimateUserOperationGas(uo controller.RPCUserOperation, entryPoint common.Address) controller.RPCGasEstimate {
+	// Same as the bundler, missing gas fields are simulated with placeholders,
+	// and the wallet checks a signature covering them.
+	op, err := uo.ToABIStruct()
+	require.NoError(s.t, err)
+	eth.FillPlaceholderGas(&op, testBlockGasLimit)
+	s.requireSigned(controller.NewRPCUserOperation(op))
+	return controller.RPCGasEstimate{
+		CallGas:            (*hexutil.Big)(big.NewInt(30000)),
+		VerificationGas:    (*hexutil.Big)(big.NewInt(60000)),
+		PreVerificationGas: (*hexutil.Big)(big.NewInt(21000)),
+	}
+}
+
+func (s *fakeServer) SendUserOperation(uo controller.RPCUserOperation, entryPoint common.Address) (common.Hash, error) {
+	op := s.requireSigned(uo)
+	s.sent = append(s.sent, uo)
+	return eth.RequestID(op, entryPoint, big.NewInt(80001))
+}
+
+func (s *fakeServer) GetUserOperationReceipt(requestID common.Hash) *controller.RPCUserOperationReceipt {
+	if s.pendingPolls > 0 {
+		s.pendingPolls--
+		return nil
+	}
+	return &controller.RPCUserOperationReceipt{RequestID: requestID, Sender: testWallet, Success: true}
+}
+
+func before_each(t *testing.T) (*Client, *fakeServer) {
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	fake := &fakeServer{t: t, key: key}
+	server := rpc.NewServer()
+	require.NoError(t, server.RegisterName("eth", fake))
+	httpServer := httptest.NewServer(server)
+	t.Cleanup(httpServer.Close)
+
+	c, err := Dial(context.Background(), httpServer.URL, httpServer.URL)
+	require.NoError(t, err)
+	return c, fake
+}
+
+func Test_ExecBatch(t *testing.T) {
+	walletABI, err := abi.SimpleWalletMetaData.GetAbi()
+	require.NoError(t, err)
+	token := common.HexToAddress("0xc0")
+
+	t.Run("wrapped in execFromEntryPoint", func(t *testing.T) {
+		callData, err := ExecBatch(testWallet, []common.Address{token}, [][]byte{{0x01}})
+		require.NoError(t, err)
+
+		method := walletABI.Methods["execFromEntryPoint"]
+		require.Equal(t, method.ID, callData[:4])
+		args, err := method.Inputs.Unpack(callData[4:])
+		require.NoError(t, err)
+		require.Equal(t, testWallet, args[0])
+
+		batch := args[2].([]byte)
+		require.Equal(t, walletABI.Methods["execBatch"].ID, batch[:4])
+		args, err = walletABI.Methods["execBatch"].Inputs.Unpack(batch[4:])
+		require.NoError(t, err)
+		require.Equal(t, []common.Address{token}, args[0])
+		require.Equal(t, [][]byte{{0x01}}, args[1])
+	})
+
+	t.Run("wrong lengths", func(t *testing.T) {
+		_, err := ExecBatch(testWallet, []common.Address{token}, nil)
+		require.Error(t, err)
+	})
+}
+
+func Test_Client(t *testing.T) {
+	t.Run("dial", func(t *testing.T) {
+		c, _ := before_each(t)
+		require.EqualValues(t, 80001, c.ChainID.Int64())
+		require.Equal(t, testEntryPoint, c.EntryPoint)
+	})
+
+	t.Run("prepare and send", func(t *testing.T) {
+		c, fake := before_each(t)
+		ctx := context.Background()
+
+		callData, err := ExecFromEntryPoint(common.HexToAddress("0xc0"), big.NewInt(1), []byte{})
+		require.NoError(t, err)
+		op := NewUserOperation(testWallet, callData)
+		require.NoError(t, c.Prepare(ctx, &op, fake.key))
+		require.EqualValues(t, 7, op.Nonce.Int64())
+		require.EqualValues(t, 2, op.MaxPriorityFeePerGas.Int64())
+		require.EqualValues(t, 202, op.MaxFeePerGas.Int64())
+		require.EqualValues(t, 60000, op.VerificationGas.Int64())
+
+		requestID, err := c.Send(ctx, op)
+		require.NoError(t, err)
+		require.Len(t, fake.sent, 1)
+
+		fake.pendingPolls = 2
+		receipt, err := c.WaitForReceipt(ctx, requestID, time.Millisecond)
+		require.NoError(t, err)
+		require.Equal(t, requestID, receipt.RequestID)
+		require.Zero(t, fake.pendingPolls)
+	})
+
+	t.Run("receipt not included before deadline", func(t *testing.T) {
+		c, fake := before_each(t)
+		fake.pendingPolls = 1000
+
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+		_, err := c.WaitForReceipt(ctx, common.Hash{}, time.Millisecond)
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+}
@@ -0,0 +1,201 @@
+package client
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"golang.org/x/xerrors"
+
+	"bundler/abi"
+	"bundler/controller"
+	"bundler/eth"
+)
+
+var errNoNode = xerrors.New("client is not connected to a node")
+
+// ExecFromEntryPoint returns `callData` making the wallet call dest with value and data.
+func ExecFromEntryPoint(dest common.Address, value *big.Int, data []byte) ([]byte, error) {
+	walletABI, err := abi.SimpleWalletMetaData.GetAbi()
+	if err != nil {
+		return nil, err
+	}
+	callData, err := walletABI.Pack("execFromEntryPoint", dest, value, data)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to pack execFromEntryPoint call: %w", err)
+	}
+	return callData, nil
+}
+
+// ExecBatch returns `callData` making wallet call every dests[i] with funcs[i].
+// `execBatch` is only callable by the wallet itself, so it is wrapped in `execFromEntryPoint` to wallet.
+func ExecBatch(wallet common.Address, dests []common.Address, funcs [][]byte) ([]byte, error) {
+	if len(dests) != len(funcs) {
+		return nil, xerrors.Errorf("%d destinations given with %d calls", len(dests), len(funcs))
+	}
+	walletABI, err := abi.SimpleWalletMetaData.GetAbi()
+	if err != nil {
+		return nil, err
+	}
+	batch, err := walletABI.Pack("execBatch", dests, funcs)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to pack execBatch call: %w", err)
+	}
+	return ExecFromEntryPoint(wallet, big.NewInt(0), batch)
+}
+
+// NewUserOperation returns an unsigned operation of sender with every other field zero.
+func NewUserOperation(sender common.Address, callData []byte) abi.UserOperation {
+	return abi.UserOperation{
+		Sender:               sender,
+		Nonce:                big.NewInt(0),
+		InitCode:             []byte{},
+		CallData:             callData,
+		CallGas:              big.NewInt(0),
+		VerificationGas:      big.NewInt(0),
+		PreVerificationGas:   big.NewInt(0),
+		MaxFeePerGas:         big.NewInt(0),
+		MaxPriorityFeePerGas: big.NewInt(0),
+		PaymasterData:        []byte{},
+		Signature:            []byte{},
+	}
+}
+
+// SignUserOperation signs request ID of op as an Ethereum signed message, same as `signUserOp` in `test/utils.ts`.
+func SignUserOperation(op abi.UserOperation, entryPoint common.Address, chainID *big.Int, key *ecdsa.PrivateKey) ([]byte, error) {
+	requestID, err := eth.RequestID(op, entryPoint, chainID)
+	if err != nil {
+		return nil, err
+	}
+	signature, err := crypto.Sign(accounts.TextHash(requestID.Bytes()), key)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to sign user operation: %w", err)
+	}
+	// `ecrecover` takes v of 27 or 28.
+	signature[64] += 27
+	return signature, nil
+}
+
+// Sign sets signature of op by key for the entry point of the client.
+func (c *Client) Sign(op *abi.UserOperation, key *ecdsa.PrivateKey) error {
+	signature, err := SignUserOperation(*op, c.EntryPoint, c.ChainID, key)
+	if err != nil {
+		return err
+	}
+	op.Signature = signature
+	return nil
+}
+
+// FillNonce sets nonce of op to current nonce of the wallet.
+// Nonce is left as-is if the wallet is not deployed yet, since it is the salt of `initCode` then.
+func (c *Client) FillNonce(ctx context.Context, op *abi.UserOperation) error {
+	if c.node == nil {
+		return errNoNode
+	}
+	code, err := c.node.CodeAt(ctx, op.Sender, nil)
+	if err != nil {
+		return xerrors.Errorf("failed to get code of wallet %s: %w", op.Sender.Hex(), err)
+	}
+	if len(code) == 0 {
+		return nil
+	}
+
+	wallet, err := abi.NewSimpleWallet(op.Sender, c.node)
+	if err != nil {
+		return err
+	}
+	nonce, err := wallet.Nonce(&bind.CallOpts{Context: ctx})
+	if err != nil {
+		return xerrors.Errorf("failed to get nonce of wallet %s: %w", op.Sender.Hex(), err)
+	}
+	op.Nonce = nonce
+	return nil
+}
+
+// FillFees sets fees of op from the node: suggested priority fee,
+// and max fee allowing base fee to double before the operation is bundled.
+func (c *Client) FillFees(ctx context.Context, op *abi.UserOperation) error {
+	if c.node == nil {
+		return errNoNode
+	}
+	header, err := c.node.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return xerrors.Errorf("failed to get latest block header: %w", err)
+	}
+	if header.BaseFee == nil {
+		gasPrice, err := c.node.SuggestGasPrice(ctx)
+		if err != nil {
+			return xerrors.Errorf("failed to get gas price: %w", err)
+		}
+		// Equal fees are treated as legacy gas price by EntryPoint.
+		op.MaxFeePerGas = gasPrice
+		op.MaxPriorityFeePerGas = big.NewInt(0).Set(gasPrice)
+		return nil
+	}
+
+	tip, err := c.node.SuggestGasTipCap(ctx)
+	if err != nil {
+		return xerrors.Errorf("failed to get priority fee: %w", err)
+	}
+	maxFee := big.NewInt(0).Mul(header.BaseFee, big.NewInt(2))
+	op.MaxFeePerGas = maxFee.Add(maxFee, tip)
+	op.MaxPriorityFeePerGas = tip
+	return nil
+}
+
+// EstimateGas sets gas limits of op from `eth_estimateUserOperationGas` of the bundler.
+// Validation runs with the given signature, so op should already be signed.
+func (c *Client) EstimateGas(ctx context.Context, op *abi.UserOperation) error {
+	estimate := controller.RPCGasEstimate{}
+	if err := c.bundler.CallContext(ctx, &estimate, "eth_estimateUserOperationGas", controller.NewRPCUserOperation(*op), c.EntryPoint); err != nil {
+		return xerrors.Errorf("failed to estimate user operation gas: %w", err)
+	}
+	op.CallGas = estimate.CallGas.ToInt()
+	op.VerificationGas = estimate.VerificationGas.ToInt()
+	op.PreVerificationGas = estimate.PreVerificationGas.ToInt()
+	return nil
+}
+
+// FillPlaceholderGas sets missing `verificationGas` and `callGas` of op to the placeholders
+// simulated by `eth_estimateUserOperationGas`: the block gas limit of the node.
+// Signatures of SimpleWallet cover gas fields, so op is signed after this to pass validation while estimating.
+func (c *Client) FillPlaceholderGas(ctx context.Context, op *abi.UserOperation) error {
+	if !eth.NeedsPlaceholderGas(*op) {
+		return nil
+	}
+	if c.node == nil {
+		return errNoNode
+	}
+	header, err := c.node.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return xerrors.Errorf("failed to get latest block header: %w", err)
+	}
+	eth.FillPlaceholderGas(op, header.GasLimit)
+	return nil
+}
+
+// Prepare fills nonce, fees and gas limits of op, then signs it with the owner key of the wallet.
+// op is signed twice, since gas is estimated with a signed operation covering placeholder gas limits.
+func (c *Client) Prepare(ctx context.Context, op *abi.UserOperation, key *ecdsa.PrivateKey) error {
+	if err := c.FillNonce(ctx, op); err != nil {
+		return err
+	}
+	if err := c.FillFees(ctx, op); err != nil {
+		return err
+	}
+	if err := c.FillPlaceholderGas(ctx, op); err != nil {
+		return err
+	}
+
+	if err := c.Sign(op, key); err != nil {
+		return err
+	}
+	if err := c.EstimateGas(ctx, op); err != nil {
+		return err
+	}
+	return c.Sign(op, key)
+}
@@ -0,0 +1,150 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"golang.org/x/xerrors"
+
+	"bundler/client"
+	"bundler/controller"
+)
+
+const defaultBundler = "http://localhost:8080"
+
+func runSend(ctx context.Context, args []string) error {
+	fs := newFlagSet("send")
+	bundler := fs.String("bundler", defaultBundler, "URL of a running bundler")
+	chainID := fs.String("chain-id", "", "Chain served by the bundler, default to its default chain")
+	entryPoint := fs.String("entry-point", "", "Entry point address, default to the default entry point of the bundler")
+	path := fs.String("op", "-", "Signed user operation JSON file, `-` for stdin")
+	wait := fs.Bool("wait", false, "Wait until the user operation is included, and print its receipt")
+	interval := fs.Duration("poll-interval", 2*time.Second, "Interval of receipt polling with --wait")
+	timeout := fs.Duration("timeout", 2*time.Minute, "Give up waiting after this long with --wait")
+	fs.Parse(args) //nolint:errcheck // ExitOnError
+
+	op, err := readOperation(*path)
+	if err != nil {
+		return err
+	}
+	c, err := client.DialBundler(ctx, bundlerURL(*bundler, *chainID, "rpc"))
+	if err != nil {
+		return err
+	}
+	if *entryPoint != "" {
+		if c.EntryPoint, err = parseAddress("entry point", *entryPoint); err != nil {
+			return err
+		}
+	}
+
+	requestID, err := c.Send(ctx, op)
+	if err != nil {
+		return err
+	}
+	if !*wait {
+		fmt.Println(requestID.Hex())
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, *timeout)
+	defer cancel()
+	receipt, err := c.WaitForReceipt(ctx, requestID, *interval)
+	if err != nil {
+		return err
+	}
+	return printJSON(receipt)
+}
+
+func runStatus(ctx context.Context, args []string) error {
+	fs := newFlagSet("status")
+	bundler := fs.String("bundler", defaultBundler, "URL of a running bundler")
+	chainID := fs.String("chain-id", "", "Chain served by the bundler, default to its default chain")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: bundlerctl status [flags] <request ID>\n\n%s.\n\nFlags:\n", commands["status"].usage)
+		fs.PrintDefaults()
+	}
+	fs.Parse(args) //nolint:errcheck // ExitOnError
+
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return xerrors.New("exactly one request ID is required")
+	}
+	requestID, err := parseHash("request ID", fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	c, err := client.DialBundler(ctx, bundlerURL(*bundler, *chainID, "rpc"))
+	if err != nil {
+		return err
+	}
+	receipt, err := c.GetReceipt(ctx, requestID)
+	if err != nil {
+		return err
+	}
+	if receipt == nil {
+		fmt.Printf("User operation %s is not included yet\n", requestID.Hex())
+		return nil
+	}
+	return printJSON(receipt)
+}
+
+func runHealth(ctx context.Context, args []string) error {
+	fs := newFlagSet("health")
+	bundler := fs.String("bundler", defaultBundler, "URL of a running bundler")
+	chainID := fs.String("chain-id", "", "Only query this chain, instead of every chain served by the bundler")
+	fs.Parse(args) //nolint:errcheck // ExitOnError
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, bundlerURL(*bundler, *chainID, "healthz"), nil)
+	if err != nil {
+		return err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return xerrors.Errorf("failed to query bundler health: %w", err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return xerrors.Errorf("failed to read bundler health: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return xerrors.Errorf("bundler is unhealthy, status %d: %s", resp.StatusCode, string(body))
+	}
+
+	indented := bytes.Buffer{}
+	if err := json.Indent(&indented, body, "", "  "); err != nil {
+		return xerrors.Errorf("failed to parse bundler health: %w", err)
+	}
+	fmt.Println(indented.String())
+	return nil
+}
+
+func runBalance(ctx context.Context, args []string) error {
+	fs := newFlagSet("balance")
+	configPath := fs.String("config", "config/config.json", "Path to config file")
+	chainID := fs.String("chain-id", "", "Configured chain to query, default to the first one")
+	fs.Parse(args) //nolint:errcheck // ExitOnError
+
+	chain, err := loadChain(*configPath, *chainID)
+	if err != nil {
+		return err
+	}
+	statuses, err := chain.BundlerStatuses(ctx)
+	if err != nil {
+		return err
+	}
+	bundlers := make([]controller.BundlerHealth, 0, len(statuses))
+	for _, status := range statuses {
+		bundlers = append(bundlers, controller.BundlerHealth{
+			Address:  status.Address.Hex(),
+			Balance:  status.Balance.String(),
+			InFlight: status.InFlight,
+		})
+	}
+	return printJSON(bundlers)
+}
@@ -0,0 +1,163 @@
+package main
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"math/big"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"syscall"
+
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/common/math"
+	"golang.org/x/xerrors"
+
+	"bundler/abi"
+	"bundler/controller"
+)
+
+type command struct {
+	usage string
+	run   func(ctx context.Context, args []string) error
+}
+
+var commands map[string]command
+
+// Commands are set in init, since their flag usages refer to commands.
+func init() {
+	commands = map[string]command{
+		"build":    {"Craft a user operation from flags or JSON", runBuild},
+		"sign":     {"Sign a user operation with a keystore file, optionally filling nonce, fees and gas first", runSign},
+		"simulate": {"Run simulateValidation of a user operation with chain config", runSimulate},
+		"send":     {"Submit a signed user operation to a running bundler", runSend},
+		"status":   {"Query execution result of a user operation by request ID", runStatus},
+		"health":   {"Query health of a running bundler", runHealth},
+		"balance":  {"Query balances of bundler EOAs with chain config", runBalance},
+	}
+}
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "Usage: bundlerctl <command> [flags]\n\nCommands:\n")
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(os.Stderr, "  %-10s %s\n", name, commands[name].usage)
+	}
+	fmt.Fprintf(os.Stderr, "\nRun `bundlerctl <command> -h` for flags of a command.\n")
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+	cmd, ok := commands[os.Args[1]]
+	if !ok {
+		usage()
+		os.Exit(2)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	if err := cmd.run(ctx, os.Args[2:]); err != nil {
+		fmt.Fprintf(os.Stderr, "bundlerctl %s: %s\n", os.Args[1], err.Error())
+		os.Exit(1)
+	}
+}
+
+func newFlagSet(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet("bundlerctl "+name, flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: bundlerctl %s [flags]\n\n%s.\n\nFlags:\n", name, commands[name].usage)
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+// readOperation reads a user operation in the JSON-RPC form, from stdin if path is `-`.
+// Missing fields other than `sender` are zero.
+func readOperation(path string) (abi.UserOperation, error) {
+	var content []byte
+	var err error
+	if path == "-" {
+		content, err = io.ReadAll(os.Stdin)
+	} else {
+		content, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return abi.UserOperation{}, xerrors.Errorf("failed to read user operation: %w", err)
+	}
+
+	uo := controller.RPCUserOperation{}
+	if err := json.Unmarshal(content, &uo); err != nil {
+		return abi.UserOperation{}, xerrors.Errorf("failed to parse user operation: %w", err)
+	}
+	return uo.ToPartialABIStruct()
+}
+
+// printJSON writes v to stdout as indented JSON.
+func printJSON(v any) error {
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
+}
+
+func printOperation(op abi.UserOperation) error {
+	return printJSON(controller.NewRPCUserOperation(op))
+}
+
+// bundlerURL returns route of a running bundler, under chainID if given.
+func bundlerURL(base string, chainID string, route string) string {
+	url := strings.TrimSuffix(base, "/")
+	if chainID != "" {
+		url += "/" + chainID
+	}
+	return url + "/" + route
+}
+
+func parseBig(name string, value string) (*big.Int, error) {
+	result, ok := math.ParseBig256(value)
+	if !ok || result.Sign() < 0 {
+		return nil, xerrors.Errorf("invalid %s %q, should be a non-negative decimal or 0x-prefixed hex number", name, value)
+	}
+	return result, nil
+}
+
+func parseAddress(name string, value string) (common.Address, error) {
+	if !common.IsHexAddress(value) {
+		return common.Address{}, xerrors.Errorf("invalid %s %q, should be a 20-byte hex address", name, value)
+	}
+	return common.HexToAddress(value), nil
+}
+
+func parseHash(name string, value string) (common.Hash, error) {
+	hash, err := hexutil.Decode(value)
+	if err != nil || len(hash) != common.HashLength {
+		return common.Hash{}, xerrors.Errorf("invalid %s %q, should be 32 bytes of 0x-prefixed hex", name, value)
+	}
+	return common.BytesToHash(hash), nil
+}
+
+// loadKey decrypts a go-ethereum keystore file with password in environment variable passwordEnv.
+func loadKey(path string, passwordEnv string) (*ecdsa.PrivateKey, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to read keystore file %s: %w", path, err)
+	}
+	key, err := keystore.DecryptKey(content, os.Getenv(passwordEnv))
+	if err != nil {
+		return nil, xerrors.Errorf("failed to decrypt keystore file %s: %w", path, err)
+	}
+	return key.PrivateKey, nil
+}
@@ -0,0 +1,169 @@
+package main
+
+import (
+	"context"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_parse(t *testing.T) {
+	t.Run("big", func(t *testing.T) {
+		for _, tc := range []struct {
+			value    string
+			expected *big.Int
+		}{
+			{"", big.NewInt(0)},
+			{"0", big.NewInt(0)},
+			{"1500000000", big.NewInt(1500000000)},
+			{"0x10", big.NewInt(16)},
+			{"0X10", big.NewInt(16)},
+		} {
+			result, err := parseBig("nonce", tc.value)
+			require.NoError(t, err, tc.value)
+			require.Equal(t, tc.expected, result, tc.value)
+		}
+
+		for _, value := range []string{"abc", "-1", "-0x1", "1.5", "0x", "0x1" + strings.Repeat("0", 64)} {
+			_, err := parseBig("nonce", value)
+			require.ErrorContains(t, err, "invalid nonce", value)
+		}
+	})
+
+	t.Run("address", func(t *testing.T) {
+		address := common.HexToAddress("0x7f477B448FA08E8801c7fe44546e6aEae9Daae19")
+		for _, value := range []string{
+			"0x7f477B448FA08E8801c7fe44546e6aEae9Daae19",
+			"0x7f477b448fa08e8801c7fe44546e6aeae9daae19",
+			"7f477b448fa08e8801c7fe44546e6aeae9daae19",
+		} {
+			result, err := parseAddress("sender", value)
+			require.NoError(t, err, value)
+			require.Equal(t, address, result, value)
+		}
+
+		for _, value := range []string{"", "0x", "0x7f477b448fa08e8801c7fe44546e6aeae9daae", "0x7f477b448fa08e8801c7fe44546e6aeae9daae1900", "0xzz477b448fa08e8801c7fe44546e6aeae9daae19"} {
+			_, err := parseAddress("sender", value)
+			require.ErrorContains(t, err, "invalid sender", value)
+		}
+	})
+
+	t.Run("hash", func(t *testing.T) {
+		value := "0xcb432201802c56f039749132d3bc69de21cb0e68842b20020073fe0edb530615"
+		result, err := parseHash("request ID", value)
+		require.NoError(t, err)
+		require.Equal(t, common.HexToHash(value), result)
+
+		for _, value := range []string{"", "0x", "cb432201802c56f039749132d3bc69de21cb0e68842b20020073fe0edb530615", "0xcb43", value + "00", "0xzz"} {
+			_, err := parseHash("request ID", value)
+			require.ErrorContains(t, err, "invalid request ID", value)
+		}
+	})
+
+	t.Run("bundler URL", func(t *testing.T) {
+		for _, tc := range []struct {
+			base, chainID, expected string
+		}{
+			{"http://localhost:8080", "", "http://localhost:8080/rpc"},
+			{"http://localhost:8080/", "", "http://localhost:8080/rpc"},
+			{"http://localhost:8080", "80001", "http://localhost:8080/80001/rpc"},
+			{"http://localhost:8080/", "80001", "http://localhost:8080/80001/rpc"},
+		} {
+			require.Equal(t, tc.expected, bundlerURL(tc.base, tc.chainID, "rpc"), tc)
+		}
+	})
+}
+
+func Test_commands(t *testing.T) {
+	ctx := context.Background()
+	sender := "0x7f477B448FA08E8801c7fe44546e6aEae9Daae19"
+
+	// writeOperation writes a user operation JSON file of sender.
+	writeOperation := func(t *testing.T) string {
+		path := filepath.Join(t.TempDir(), "op.json")
+		require.NoError(t, os.WriteFile(path, []byte(`{"sender":"`+sender+`"}`), 0600))
+		return path
+	}
+	// writeKeystore writes a keystore file encrypted with password in `BUNDLERCTL_PASSWORD`.
+	writeKeystore := func(t *testing.T) string {
+		t.Setenv("BUNDLERCTL_PASSWORD", "password")
+		ks := keystore.NewKeyStore(t.TempDir(), keystore.LightScryptN, keystore.LightScryptP)
+		account, err := ks.NewAccount("password")
+		require.NoError(t, err)
+		return account.URL.Path
+	}
+
+	t.Run("build", func(t *testing.T) {
+		for _, tc := range []struct {
+			name string
+			args []string
+			err  string
+		}{
+			{"no sender", []string{}, "--sender is required"},
+			{"no sender with to", []string{"--to", "0xc0c0c0c0c0c0c0c0c0c0c0c0c0c0c0c0c0c0c0c0"}, "--sender is required"},
+			{"to and call data", []string{"--sender", sender, "--to", "0xc0c0c0c0c0c0c0c0c0c0c0c0c0c0c0c0c0c0c0c0", "--call-data", "0x01"}, "--to and --call-data cannot be both given"},
+			{"invalid sender", []string{"--sender", "0x01"}, "invalid sender"},
+			{"invalid nonce", []string{"--sender", sender, "--nonce", "abc"}, "invalid nonce"},
+			{"invalid bytes", []string{"--sender", sender, "--init-code", "01"}, "invalid init-code"},
+			{"invalid to", []string{"--sender", sender, "--to", "0x01"}, "invalid to"},
+			{"invalid value", []string{"--sender", sender, "--to", "0xc0c0c0c0c0c0c0c0c0c0c0c0c0c0c0c0c0c0c0c0", "--value", "-1"}, "invalid value"},
+			{"invalid data", []string{"--sender", sender, "--to", "0xc0c0c0c0c0c0c0c0c0c0c0c0c0c0c0c0c0c0c0c0", "--data", "0x0"}, "invalid data"},
+			{"missing op file", []string{"--op", filepath.Join(t.TempDir(), "missing.json")}, "failed to read user operation"},
+		} {
+			t.Run(tc.name, func(t *testing.T) {
+				require.ErrorContains(t, runBuild(ctx, tc.args), tc.err)
+			})
+		}
+
+		t.Run("valid", func(t *testing.T) {
+			require.NoError(t, runBuild(ctx, []string{"--sender", sender, "--to", "0xc0c0c0c0c0c0c0c0c0c0c0c0c0c0c0c0c0c0c0c0", "--value", "0x10"}))
+			require.NoError(t, runBuild(ctx, []string{"--op", writeOperation(t), "--nonce", "1"}))
+		})
+	})
+
+	t.Run("sign", func(t *testing.T) {
+		t.Run("no keystore", func(t *testing.T) {
+			require.ErrorContains(t, runSign(ctx, []string{"--op", writeOperation(t)}), "--keystore is required")
+		})
+
+		t.Run("no chain", func(t *testing.T) {
+			op, keystorePath := writeOperation(t), writeKeystore(t)
+			for _, args := range [][]string{
+				{},
+				{"--chain-id", "80001"},
+				{"--entry-point", "0x8A42F70047a99298822dD1dbA34b454fc49913F2"},
+			} {
+				args = append([]string{"--op", op, "--keystore", keystorePath}, args...)
+				require.ErrorContains(t, runSign(ctx, args), "--chain-id and --entry-point are required without --bundler", args)
+			}
+		})
+
+		t.Run("wrong password", func(t *testing.T) {
+			op, keystorePath := writeOperation(t), writeKeystore(t)
+			t.Setenv("BUNDLERCTL_PASSWORD", "wrong")
+			require.ErrorContains(t, runSign(ctx, []string{"--op", op, "--keystore", keystorePath}), "failed to decrypt keystore file")
+		})
+
+		t.Run("offline", func(t *testing.T) {
+			op, keystorePath := writeOperation(t), writeKeystore(t)
+			args := []string{"--op", op, "--keystore", keystorePath, "--chain-id", "80001", "--entry-point", "0x8A42F70047a99298822dD1dbA34b454fc49913F2"}
+			require.NoError(t, runSign(ctx, args))
+
+			args[len(args)-1] = "0x01"
+			require.ErrorContains(t, runSign(ctx, args), "invalid entry point")
+		})
+	})
+
+	t.Run("status", func(t *testing.T) {
+		for _, args := range [][]string{{}, {"0x01", "0x02"}} {
+			require.ErrorContains(t, runStatus(ctx, args), "exactly one request ID is required", args)
+		}
+		require.ErrorContains(t, runStatus(ctx, []string{"0x01"}), "invalid request ID")
+	})
+}
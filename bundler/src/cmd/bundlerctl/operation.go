@@ -0,0 +1,233 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"golang.org/x/xerrors"
+
+	"bundler/abi"
+	"bundler/client"
+	"bundler/config"
+	"bundler/eth"
+)
+
+type operationFlag struct {
+	name  string
+	usage string
+	set   func(op *abi.UserOperation, value string) error
+}
+
+func bigFlag(name string, usage string, field func(op *abi.UserOperation) **big.Int) operationFlag {
+	return operationFlag{name, usage, func(op *abi.UserOperation, value string) (err error) {
+		*field(op), err = parseBig(name, value)
+		return err
+	}}
+}
+
+func bytesFlag(name string, usage string, field func(op *abi.UserOperation) *[]byte) operationFlag {
+	return operationFlag{name, usage, func(op *abi.UserOperation, value string) (err error) {
+		*field(op), err = hexutil.Decode(value)
+		if err != nil {
+			return xerrors.Errorf("invalid %s %q: %w", name, value, err)
+		}
+		return nil
+	}}
+}
+
+func addressFlag(name string, usage string, field func(op *abi.UserOperation) *common.Address) operationFlag {
+	return operationFlag{name, usage, func(op *abi.UserOperation, value string) (err error) {
+		*field(op), err = parseAddress(name, value)
+		return err
+	}}
+}
+
+// operationFlags set each field of a user operation. Numbers are decimal or 0x-prefixed hex, bytes are 0x-prefixed hex.
+var operationFlags = []operationFlag{
+	addressFlag("sender", "Wallet address", func(op *abi.UserOperation) *common.Address { return &op.Sender }),
+	bigFlag("nonce", "Wallet nonce", func(op *abi.UserOperation) **big.Int { return &op.Nonce }),
+	bytesFlag("init-code", "Wallet creation code, if not deployed yet", func(op *abi.UserOperation) *[]byte { return &op.InitCode }),
+	bytesFlag("call-data", "Calldata of the call to wallet", func(op *abi.UserOperation) *[]byte { return &op.CallData }),
+	bigFlag("call-gas", "Gas limit of the call to wallet", func(op *abi.UserOperation) **big.Int { return &op.CallGas }),
+	bigFlag("verification-gas", "Gas limit of validation", func(op *abi.UserOperation) **big.Int { return &op.VerificationGas }),
+	bigFlag("pre-verification-gas", "Gas paid for calldata and bundle overhead", func(op *abi.UserOperation) **big.Int { return &op.PreVerificationGas }),
+	bigFlag("max-fee-per-gas", "EIP-1559 max fee per gas in wei", func(op *abi.UserOperation) **big.Int { return &op.MaxFeePerGas }),
+	bigFlag("max-priority-fee-per-gas", "EIP-1559 max priority fee per gas in wei", func(op *abi.UserOperation) **big.Int { return &op.MaxPriorityFeePerGas }),
+	addressFlag("paymaster", "Paymaster address, zero if the wallet pays", func(op *abi.UserOperation) *common.Address { return &op.Paymaster }),
+	bytesFlag("paymaster-data", "Data given to paymaster", func(op *abi.UserOperation) *[]byte { return &op.PaymasterData }),
+	bytesFlag("signature", "Wallet signature", func(op *abi.UserOperation) *[]byte { return &op.Signature }),
+}
+
+func runBuild(ctx context.Context, args []string) error {
+	fs := newFlagSet("build")
+	base := fs.String("op", "", "Start from a user operation JSON file, `-` for stdin")
+	to := fs.String("to", "", "Make callData execFromEntryPoint(to, value, data) of SimpleWallet")
+	value := fs.String("value", "0", "Wei sent with --to")
+	data := fs.String("data", "0x", "Calldata of the call to --to")
+	values := make(map[string]*string, len(operationFlags))
+	for _, f := range operationFlags {
+		values[f.name] = fs.String(f.name, "", f.usage)
+	}
+	fs.Parse(args) //nolint:errcheck // ExitOnError
+
+	op := client.NewUserOperation(common.Address{}, []byte{})
+	if *base != "" {
+		var err error
+		if op, err = readOperation(*base); err != nil {
+			return err
+		}
+	}
+
+	set := map[string]bool{}
+	fs.Visit(func(f *flag.Flag) { set[f.Name] = true })
+	for _, f := range operationFlags {
+		if !set[f.name] {
+			continue
+		}
+		if err := f.set(&op, *values[f.name]); err != nil {
+			return err
+		}
+	}
+
+	if *to != "" {
+		if set["call-data"] {
+			return xerrors.New("--to and --call-data cannot be both given")
+		}
+		dest, err := parseAddress("to", *to)
+		if err != nil {
+			return err
+		}
+		amount, err := parseBig("value", *value)
+		if err != nil {
+			return err
+		}
+		callData, err := hexutil.Decode(*data)
+		if err != nil {
+			return xerrors.Errorf("invalid data %q: %w", *data, err)
+		}
+		if op.CallData, err = client.ExecFromEntryPoint(dest, amount, callData); err != nil {
+			return err
+		}
+	}
+
+	if op.Sender == (common.Address{}) {
+		return xerrors.New("--sender is required")
+	}
+	return printOperation(op)
+}
+
+func runSign(ctx context.Context, args []string) error {
+	fs := newFlagSet("sign")
+	path := fs.String("op", "-", "User operation JSON file, `-` for stdin")
+	keystorePath := fs.String("keystore", "", "go-ethereum keystore file of the wallet owner")
+	passwordEnv := fs.String("password-env", "BUNDLERCTL_PASSWORD", "Environment variable holding password of keystore file")
+	bundler := fs.String("bundler", "", "URL of a running bundler to read chain ID and entry point from, e.g. http://localhost:8080")
+	node := fs.String("node", "", "RPC server of the chain. Nonce, fees and gas are filled before signing if given with --bundler")
+	chainID := fs.String("chain-id", "", "Chain ID. Required without --bundler, chooses the chain served by --bundler otherwise")
+	entryPoint := fs.String("entry-point", "", "Entry point address. Required without --bundler, default entry point of --bundler otherwise")
+	fs.Parse(args) //nolint:errcheck // ExitOnError
+
+	if *keystorePath == "" {
+		return xerrors.New("--keystore is required")
+	}
+	op, err := readOperation(*path)
+	if err != nil {
+		return err
+	}
+	key, err := loadKey(*keystorePath, *passwordEnv)
+	if err != nil {
+		return err
+	}
+
+	var c *client.Client
+	switch {
+	case *bundler != "" && *node != "":
+		c, err = client.Dial(ctx, bundlerURL(*bundler, *chainID, "rpc"), *node)
+	case *bundler != "":
+		c, err = client.DialBundler(ctx, bundlerURL(*bundler, *chainID, "rpc"))
+	case *chainID == "" || *entryPoint == "":
+		return xerrors.New("--chain-id and --entry-point are required without --bundler")
+	default:
+		var id *big.Int
+		if id, err = parseBig("chain ID", *chainID); err != nil {
+			return err
+		}
+		c = client.New(nil, nil, id, common.Address{})
+	}
+	if err != nil {
+		return err
+	}
+	if *entryPoint != "" {
+		if c.EntryPoint, err = parseAddress("entry point", *entryPoint); err != nil {
+			return err
+		}
+	}
+
+	if *bundler != "" && *node != "" {
+		err = c.Prepare(ctx, &op, key)
+	} else {
+		err = c.Sign(&op, key)
+	}
+	if err != nil {
+		return err
+	}
+	return printOperation(op)
+}
+
+// loadChain connects to chain chainID in config file, the first configured chain if chainID is empty.
+func loadChain(configPath string, chainID string) (*eth.Chain, error) {
+	if err := config.Load(configPath); err != nil {
+		return nil, err
+	}
+	chainConfig := config.GetChains()[0]
+	if chainID != "" {
+		id, err := parseBig("chain ID", chainID)
+		if err != nil {
+			return nil, err
+		}
+		if chainConfig = config.GetChain(id); chainConfig == nil {
+			return nil, xerrors.Errorf("chain %s is not configured", chainID)
+		}
+	}
+	return eth.NewChain(chainConfig)
+}
+
+func runSimulate(ctx context.Context, args []string) error {
+	fs := newFlagSet("simulate")
+	configPath := fs.String("config", "config/config.json", "Path to config file")
+	chainID := fs.String("chain-id", "", "Configured chain to simulate on, default to the first one")
+	entryPoint := fs.String("entry-point", "", "Entry point address, default to the first configured one")
+	path := fs.String("op", "-", "User operation JSON file, `-` for stdin")
+	fs.Parse(args) //nolint:errcheck // ExitOnError
+
+	op, err := readOperation(*path)
+	if err != nil {
+		return err
+	}
+	chain, err := loadChain(*configPath, *chainID)
+	if err != nil {
+		return err
+	}
+	entrypoint := chain.DefaultEntryPoint()
+	if *entryPoint != "" {
+		address, err := parseAddress("entry point", *entryPoint)
+		if err != nil {
+			return err
+		}
+		if entrypoint = chain.EntryPoint(address); entrypoint == nil {
+			return xerrors.Errorf("entry point %s is not configured", address.Hex())
+		}
+	}
+
+	result, err := entrypoint.Simulate(ctx, op)
+	if err != nil {
+		return xerrors.Errorf("failed to simulate user operation: %w", err)
+	}
+	return printJSON(map[string]string{
+		"preOpGas": result.PreOpGas.String(),
+		"prefund":  result.Prefund.String(),
+	})
+}
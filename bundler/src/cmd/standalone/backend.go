@@ -0,0 +1,36 @@
+package main
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+
+	"bundler/eth"
+)
+
+// txBackend implements `mempool.TxBackend` with an eth.Chain.
+type txBackend struct {
+	chain *eth.Chain
+}
+
+func (b txBackend) BlockNumber(ctx context.Context) (uint64, error) {
+	return b.chain.BlockNumber(ctx)
+}
+
+func (b txBackend) NonceAt(ctx context.Context, account common.Address) (uint64, error) {
+	return b.chain.NonceAt(ctx, account)
+}
+
+func (b txBackend) Receipt(ctx context.Context, tx *types.Transaction) (*types.Receipt, *big.Int, error) {
+	return b.chain.TransactionReceipt(ctx, tx)
+}
+
+func (b txBackend) Resend(ctx context.Context, tx *types.Transaction) (*types.Transaction, error) {
+	return b.chain.ResendTransaction(ctx, tx)
+}
+
+func (b txBackend) ResyncNonce(ctx context.Context, account common.Address) error {
+	return b.chain.ResyncNonce(ctx, account)
+}
@@ -1,11 +1,145 @@
 package main
 
 import (
+	"bundler/abi"
 	"bundler/config"
+	"bundler/controller"
 	"bundler/eth"
+	"bundler/mempool"
+	"context"
+	"errors"
+	"flag"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+var (
+	l = logrus.WithField("module", "standalone")
+
+	flagConfig          = flag.String("config", "config/config.json", "Path to config file")
+	flagListen          = flag.String("listen", ":8080", "Address for HTTP server to listen on")
+	flagShutdownTimeout = flag.Duration("shutdown-timeout", 10*time.Second, "Time to wait for in-flight requests when shutting down")
 )
 
 func main() {
-	config.InitFromFile("config/config.json")
+	flag.Parse()
+
+	config.InitFromFile(*flagConfig)
 	eth.Init()
+
+	server := &http.Server{
+		Addr:              *flagListen,
+		Handler:           controller.NewRouter(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	poolDone := make(chan struct{})
+	if config.C.Mempool != nil {
+		wg := sync.WaitGroup{}
+		for _, chain := range eth.Chains() {
+			for _, entrypoint := range chain.EntryPoints {
+				runMempool(ctx, entrypoint, &wg)
+			}
+		}
+		go func() {
+			defer close(poolDone)
+			wg.Wait()
+		}()
+		l.Infof("Mempool enabled, bundle interval: %s, max bundle size: %d", config.GetBundleInterval(), config.GetMaxBundleSize())
+	} else {
+		wg := sync.WaitGroup{}
+		for _, chain := range eth.Chains() {
+			for _, entrypoint := range chain.EntryPoints {
+				runTracker(ctx, entrypoint, &wg)
+			}
+		}
+		go func() {
+			defer close(poolDone)
+			wg.Wait()
+		}()
+	}
+
+	go func() {
+		l.Infof("Listening on %s", *flagListen)
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			l.Fatalf("HTTP server error: %s", err.Error())
+		}
+	}()
+
+	<-ctx.Done()
+	l.Infof("Shutting down")
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), *flagShutdownTimeout)
+	defer cancel()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		l.Errorf("Graceful shutdown failed: %s", err.Error())
+	}
+	<-poolDone
+}
+
+// runMempool starts bundling loop and tracker of an entry point until ctx is done.
+// wg is done once both are stopped and the store is closed.
+func runMempool(ctx context.Context, entrypoint *eth.EntryPoint, wg *sync.WaitGroup) {
+	dataDir, err := config.GetMempoolDataDir(entrypoint.Config, entrypoint.Address)
+	if err != nil {
+		l.Fatalf("%s", err.Error())
+	}
+	store := mempool.NewMemoryStore()
+	if dataDir != "" {
+		store, err = mempool.NewLevelDBStore(dataDir)
+		if err != nil {
+			l.Fatalf("%s", err.Error())
+		}
+	}
+
+	resimulate := func(ctx context.Context, op abi.UserOperation) error {
+		_, err := entrypoint.Simulate(ctx, op)
+		return err
+	}
+	pool := mempool.New(store, config.GetBundleInterval(), config.GetMaxBundleSize(), entrypoint.SendHandleOps, resimulate)
+	if err := pool.Restore(ctx); err != nil {
+		l.Fatalf("%s", err.Error())
+	}
+	controller.UseMempool(entrypoint, pool)
+	tracker := mempool.NewTracker(store, txBackend{chain: entrypoint.Chain}, config.GetResendAfterBlocks(), config.GetMaxResends(), false)
+
+	running := sync.WaitGroup{}
+	running.Add(2)
+	go func() {
+		defer running.Done()
+		pool.Run(ctx)
+	}()
+	go func() {
+		defer running.Done()
+		tracker.Run(ctx, config.GetBundleInterval())
+	}()
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		running.Wait()
+		store.Close()
+	}()
+}
+
+// runTracker tracks bundles sent immediately to an entry point without mempool, until ctx is done.
+// Records are kept in memory only, since `data_dir` is part of mempool config,
+// and are evicted once bundles are mined or dropped, since nothing else reads them.
+func runTracker(ctx context.Context, entrypoint *eth.EntryPoint, wg *sync.WaitGroup) {
+	tracker := mempool.NewTracker(mempool.NewMemoryStore(), txBackend{chain: entrypoint.Chain}, config.GetResendAfterBlocks(), config.GetMaxResends(), true)
+	controller.UseTracker(entrypoint, tracker)
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		tracker.Run(ctx, config.GetBundleInterval())
+	}()
 }
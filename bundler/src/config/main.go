@@ -2,26 +2,50 @@ package config
 
 import (
 	"context"
-	"crypto/ecdsa"
 	"encoding/json"
 	"fmt"
 	"math/big"
 	"os"
+	"path/filepath"
+	"sync"
+	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
 	"github.com/ethereum/go-ethereum/common"
-	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/common/math"
 	"github.com/sirupsen/logrus"
+
+	"bundler/signer"
+)
+
+const (
+	DefaultLogLookbackBlocks = 10000
+	DefaultBundleInterval    = 10 * time.Second
+	DefaultMaxBundleSize     = 10
+	DefaultGasLimitMargin    = 10
+	DefaultResendAfterBlocks = 5
+	DefaultMaxResends        = 5
 )
 
 var (
 	C *Config
+
+	signersMu sync.Mutex
+	signers   map[*ChainConfig][]signer.Signer
+	// Config which signers are created from.
+	signersConfig *Config
 )
 
 type Config struct {
+	// Single chain config, kept for backward compatibility. Served before `chains` if given.
 	Chain ChainConfig `json:"chain"`
+	// Chains served by one deployment, each with its own RPC server, entry point and keys.
+	Chains []ChainConfig `json:"chains"`
+	// Mempool can be nil. If so, operations are sent in one transaction per request.
+	// Only used by standalone server, since Lambda cannot run background bundling loop.
+	Mempool *MempoolConfig `json:"mempool"`
 	// Test can be nil in production env.
 	Test *TestConfig `json:"test"`
 }
@@ -31,6 +55,66 @@ type ChainConfig struct {
 	RPCServer                 string `json:"rpc_server"`
 	SecretKey                 string `json:"secret_key"`
 	EntrypointContractAddress string `json:"entrypoint_contract_address"`
+	// More entry points served besides `entrypoint_contract_address`, e.g. previous deployments.
+	// Each entry point has its own mempool.
+	EntrypointContractAddresses []string `json:"entrypoint_contract_addresses"`
+	// More bundler keys, so that several bundles can be pending at the same time.
+	// Optional, `secret_key` is used first if both are given.
+	SecretKeys []string `json:"secret_keys"`
+	// Bundler signers not holding a raw key in config. Optional, used after `secret_keys`.
+	Signers []SignerConfig `json:"signers"`
+	// How many blocks to look back when searching for UserOperation events.
+	// Optional, default to `DefaultLogLookbackBlocks`.
+	LogLookbackBlocks uint64 `json:"log_lookback_blocks"`
+	// Trace `simulateValidation` with `debug_traceCall` to enforce banned opcode rules.
+	// RPC server must support geth JS tracers.
+	TraceValidation bool `json:"trace_validation"`
+	// Signatures of deployed wallets with `owner()` are checked off-chain like SimpleWallet before simulation.
+	// Skip it if served wallets have `owner()` but verify signatures differently.
+	SkipWalletSignatureCheck bool `json:"skip_wallet_signature_check"`
+	// Percentage added to estimated gas limit of `handleOps` transaction.
+	// Optional, default to `DefaultGasLimitMargin`.
+	GasLimitMargin uint64 `json:"gas_limit_margin"`
+}
+
+const (
+	SignerLocal    = "local"
+	SignerKeystore = "keystore"
+	SignerExternal = "external"
+)
+
+type SignerConfig struct {
+	// One of `SignerLocal`, `SignerKeystore` or `SignerExternal`.
+	Type string `json:"type"`
+	// Hex private key, for local signer.
+	SecretKey string `json:"secret_key"`
+	// Path of go-ethereum encrypted key file, for keystore signer.
+	KeystoreFile string `json:"keystore_file"`
+	// Name of environment variable holding password of keystore file.
+	PasswordEnv string `json:"password_env"`
+	// JSON-RPC endpoint supporting `account_signTransaction`, e.g. Clef, for external signer.
+	URL string `json:"url"`
+	// Account to sign with, for external signer.
+	Address string `json:"address"`
+}
+
+type MempoolConfig struct {
+	// Seconds between two bundles. Optional, default to `DefaultBundleInterval`.
+	BundleInterval uint64 `json:"bundle_interval"`
+	// A bundle is sent immediately once this many operations are pending.
+	// Optional, default to `DefaultMaxBundleSize`.
+	MaxBundleSize int `json:"max_bundle_size"`
+	// Directory of on-disk mempool database.
+	// Optional, pending operations are lost on restart if not set.
+	// Each entry point is stored in `<chain ID>/<entry point>` under this directory, with chain ID in decimal,
+	// except a config with only `chain` and a single entry point, which uses this directory itself.
+	DataDir string `json:"data_dir"`
+	// A bundle transaction not mined after this many blocks is re-sent with bumped fees.
+	// Used by standalone server only, bundles sent by Lambda are not tracked.
+	// Optional, default to `DefaultResendAfterBlocks`.
+	ResendAfterBlocks uint64 `json:"resend_after_blocks"`
+	// Optional, default to `DefaultMaxResends`.
+	MaxResends int `json:"max_resends"`
 }
 
 type TestConfig struct {
@@ -45,21 +129,48 @@ func InitFromFile(filename string) {
 		return
 	}
 
+	if err := Load(filename); err != nil {
+		panic(err.Error())
+	}
+
+	for _, chain := range GetChains() {
+		fmt.Printf("Chain ID: %s\n", chain.GetChainID().String())
+		for _, address := range chain.GetBundlerAddresses() {
+			fmt.Printf("Bundler EOA address: %s\n", address.Hex())
+		}
+		for _, address := range chain.GetEntrypointContractAddresses() {
+			fmt.Printf("Entrypoint contract address: %s\n", address.Hex())
+		}
+	}
+}
+
+// Load reads config file into C without printing it, e.g. for command line tools.
+// Invalid chain IDs and bundler signers are returned as errors.
+func Load(filename string) error {
 	configContent, err := os.ReadFile(filename)
 	if err != nil {
-		panic(fmt.Sprintf("Error reading config file: %v", err))
+		return fmt.Errorf("failed to read config file: %w", err)
 	}
 
 	if err = json.Unmarshal(configContent, &C); err != nil {
-		panic(fmt.Sprintf("Error parsing config file: %v", err))
+		return fmt.Errorf("failed to parse config file: %w", err)
 	}
 
-	GetChainID() // Check Chain ID config
-	GetBundler() // Check bundler config
-
-	fmt.Printf("Bundler EOA address: %s\n", GetBundlerAddress().Hex())
-	fmt.Printf("Entrypoint contract address: %s\n", GetEntrypointContractAddress().Hex())
-
+	chainIDs := make(map[string]bool)
+	for _, chain := range GetChains() {
+		chainID, err := chain.ParseChainID()
+		if err != nil {
+			return err
+		}
+		if chainIDs[chainID.String()] {
+			return fmt.Errorf("chain %s configured more than once", chainID.String())
+		}
+		chainIDs[chainID.String()] = true
+		if _, err := chain.LoadSigners(); err != nil {
+			return fmt.Errorf("invalid bundler config of chain %s: %w", chainID.String(), err)
+		}
+	}
+	return nil
 }
 
 func InitFromAWSSecret() {
@@ -101,28 +212,228 @@ func InitFromAWSSecret() {
 	}
 }
 
-func GetChainID() *big.Int {
-	id, ok := big.NewInt(0).SetString(C.Chain.ChainID, 10)
+// GetChains returns config of every chain, `chain` first, then `chains`.
+func GetChains() []*ChainConfig {
+	chains := make([]*ChainConfig, 0, len(C.Chains)+1)
+	if C.Chain.ChainID != "" || len(C.Chains) == 0 {
+		chains = append(chains, &C.Chain)
+	}
+	for i := range C.Chains {
+		chains = append(chains, &C.Chains[i])
+	}
+	return chains
+}
+
+// GetChain returns config of chain with given ID, nil if the chain is not configured.
+func GetChain(chainID *big.Int) *ChainConfig {
+	for _, chain := range GetChains() {
+		if chain.GetChainID().Cmp(chainID) == 0 {
+			return chain
+		}
+	}
+	return nil
+}
+
+// ParseChainID parses `id` in decimal or 0x-prefixed hex.
+func (c *ChainConfig) ParseChainID() (*big.Int, error) {
+	id, ok := math.ParseBig256(c.ChainID)
 	if !ok {
-		panic(fmt.Sprintf("failed to parse chain id: %v", C.Chain.ChainID))
+		return nil, fmt.Errorf("failed to parse chain id: %q", c.ChainID)
+	}
+	return id, nil
+}
+
+// GetChainID is ParseChainID of a loaded config, whose chain IDs are already checked.
+func (c *ChainConfig) GetChainID() *big.Int {
+	id, err := c.ParseChainID()
+	if err != nil {
+		panic(err.Error())
 	}
 	return id
 }
 
-func GetBundler() *ecdsa.PrivateKey {
-	skBytes := common.Hex2Bytes(C.Chain.SecretKey)
-	sk, err := crypto.ToECDSA(skBytes)
+func newSigner(signerConfig SignerConfig) (signer.Signer, error) {
+	switch signerConfig.Type {
+	case SignerLocal:
+		return signer.NewLocalFromHex(signerConfig.SecretKey)
+	case SignerKeystore:
+		return signer.NewKeystore(signerConfig.KeystoreFile, os.Getenv(signerConfig.PasswordEnv))
+	case SignerExternal:
+		if !common.IsHexAddress(signerConfig.Address) {
+			return nil, fmt.Errorf("invalid external signer address: %s", signerConfig.Address)
+		}
+		return signer.NewExternal(signerConfig.URL, common.HexToAddress(signerConfig.Address))
+	default:
+		return nil, fmt.Errorf("unknown signer type: %s", signerConfig.Type)
+	}
+}
+
+// GetSigners returns all bundler signers of the chain: `secret_key` first, then `secret_keys`, then `signers`.
+// Signers are created once per config.
+func (c *ChainConfig) GetSigners() []signer.Signer {
+	result, err := c.LoadSigners()
 	if err != nil {
-		panic(fmt.Sprintf("failed to parse paymaster secret key: %v", err))
+		panic(err.Error())
 	}
-	return sk
+	return result
 }
 
-func GetBundlerAddress() common.Address {
-	sk := GetBundler()
-	return crypto.PubkeyToAddress(sk.PublicKey)
+// LoadSigners is GetSigners returning invalid signer config as error.
+func (c *ChainConfig) LoadSigners() ([]signer.Signer, error) {
+	signersMu.Lock()
+	defer signersMu.Unlock()
+	if signersConfig != C {
+		signers = make(map[*ChainConfig][]signer.Signer)
+		signersConfig = C
+	}
+	if result, ok := signers[c]; ok {
+		return result, nil
+	}
+
+	// `secret_key` and `secret_keys` are local signers.
+	signerConfigs := make([]SignerConfig, 0, len(c.SecretKeys)+len(c.Signers)+1)
+	if c.SecretKey != "" {
+		signerConfigs = append(signerConfigs, SignerConfig{Type: SignerLocal, SecretKey: c.SecretKey})
+	}
+	for _, secretKey := range c.SecretKeys {
+		signerConfigs = append(signerConfigs, SignerConfig{Type: SignerLocal, SecretKey: secretKey})
+	}
+	signerConfigs = append(signerConfigs, c.Signers...)
+
+	result := make([]signer.Signer, 0, len(signerConfigs))
+	for _, signerConfig := range signerConfigs {
+		s, err := newSigner(signerConfig)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create bundler signer: %w", err)
+		}
+		result = append(result, s)
+	}
+	if len(result) == 0 {
+		return nil, fmt.Errorf("no bundler secret key or signer configured for chain %s", c.ChainID)
+	}
+
+	signers[c] = result
+	return result, nil
 }
 
-func GetEntrypointContractAddress() common.Address {
-	return common.HexToAddress(C.Chain.EntrypointContractAddress)
+// GetSigner returns signer of a bundler address, nil if address is not a bundler.
+func (c *ChainConfig) GetSigner(address common.Address) signer.Signer {
+	for _, s := range c.GetSigners() {
+		if s.Address() == address {
+			return s
+		}
+	}
+	return nil
+}
+
+// GetBundlerAddress returns address of the first bundler signer.
+func (c *ChainConfig) GetBundlerAddress() common.Address {
+	return c.GetSigners()[0].Address()
+}
+
+func (c *ChainConfig) GetBundlerAddresses() []common.Address {
+	all := c.GetSigners()
+	addresses := make([]common.Address, 0, len(all))
+	for _, s := range all {
+		addresses = append(addresses, s.Address())
+	}
+	return addresses
+}
+
+// GetEntrypointContractAddress returns the default entry point of the chain.
+func (c *ChainConfig) GetEntrypointContractAddress() common.Address {
+	return c.GetEntrypointContractAddresses()[0]
+}
+
+// GetEntrypointContractAddresses returns every entry point of the chain,
+// `entrypoint_contract_address` first, then `entrypoint_contract_addresses`.
+func (c *ChainConfig) GetEntrypointContractAddresses() []common.Address {
+	addresses := make([]common.Address, 0, len(c.EntrypointContractAddresses)+1)
+	seen := make(map[common.Address]bool)
+	add := func(address common.Address) {
+		if !seen[address] {
+			seen[address] = true
+			addresses = append(addresses, address)
+		}
+	}
+	if c.EntrypointContractAddress != "" || len(c.EntrypointContractAddresses) == 0 {
+		add(common.HexToAddress(c.EntrypointContractAddress))
+	}
+	for _, address := range c.EntrypointContractAddresses {
+		add(common.HexToAddress(address))
+	}
+	return addresses
+}
+
+func (c *ChainConfig) GetLogLookbackBlocks() uint64 {
+	if c.LogLookbackBlocks == 0 {
+		return DefaultLogLookbackBlocks
+	}
+	return c.LogLookbackBlocks
+}
+
+func (c *ChainConfig) GetGasLimitMargin() uint64 {
+	if c.GasLimitMargin == 0 {
+		return DefaultGasLimitMargin
+	}
+	return c.GasLimitMargin
+}
+
+func GetBundleInterval() time.Duration {
+	if C.Mempool == nil || C.Mempool.BundleInterval == 0 {
+		return DefaultBundleInterval
+	}
+	return time.Duration(C.Mempool.BundleInterval) * time.Second
+}
+
+func GetMaxBundleSize() int {
+	if C.Mempool == nil || C.Mempool.MaxBundleSize <= 0 {
+		return DefaultMaxBundleSize
+	}
+	return C.Mempool.MaxBundleSize
+}
+
+// GetMempoolDataDir returns mempool database directory of an entry point, empty if mempool is not persisted.
+// Every entry point is stored in its own `data_dir/<chain ID>/<entry point>`, so that no database is nested in another.
+// Only a config with the single `chain` and a single entry point keeps using `data_dir` itself,
+// so that existing databases are still found.
+func GetMempoolDataDir(chain *ChainConfig, entrypoint common.Address) (string, error) {
+	if C.Mempool == nil || C.Mempool.DataDir == "" {
+		return "", nil
+	}
+	dataDir := C.Mempool.DataDir
+	if chain == &C.Chain && len(C.Chains) == 0 && len(chain.GetEntrypointContractAddresses()) == 1 {
+		// Legacy layout
+		return dataDir, nil
+	}
+
+	chainDir := filepath.Join(dataDir, chain.GetChainID().String())
+	if isLevelDB(dataDir) {
+		return "", fmt.Errorf("data_dir %s holds the mempool database of a single chain config, move it to data_dir/<chain ID>/<entry point>", dataDir)
+	}
+	if isLevelDB(chainDir) {
+		return "", fmt.Errorf("%s holds the mempool database of the default entry point, move it to %s",
+			chainDir, filepath.Join(chainDir, chain.GetEntrypointContractAddress().Hex()))
+	}
+	return filepath.Join(chainDir, entrypoint.Hex()), nil
+}
+
+// isLevelDB reports whether dir holds a LevelDB database.
+func isLevelDB(dir string) bool {
+	_, err := os.Stat(filepath.Join(dir, "CURRENT"))
+	return err == nil
+}
+
+func GetResendAfterBlocks() uint64 {
+	if C.Mempool == nil || C.Mempool.ResendAfterBlocks == 0 {
+		return DefaultResendAfterBlocks
+	}
+	return C.Mempool.ResendAfterBlocks
+}
+
+func GetMaxResends() int {
+	if C.Mempool == nil || C.Mempool.MaxResends <= 0 {
+		return DefaultMaxResends
+	}
+	return C.Mempool.MaxResends
 }
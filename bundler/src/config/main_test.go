@@ -0,0 +1,179 @@
+package config
+
+import (
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_GetChains(t *testing.T) {
+	dataDir := func(chain *ChainConfig, entrypoint common.Address) string {
+		dir, err := GetMempoolDataDir(chain, entrypoint)
+		require.NoError(t, err)
+		return dir
+	}
+
+	t.Run("legacy chain", func(t *testing.T) {
+		C = &Config{
+			Chain:   ChainConfig{ChainID: "80001"},
+			Mempool: &MempoolConfig{DataDir: "data"},
+		}
+		chains := GetChains()
+		require.Len(t, chains, 1)
+		require.Equal(t, "data", dataDir(chains[0], chains[0].GetEntrypointContractAddress()))
+	})
+
+	t.Run("chains", func(t *testing.T) {
+		C = &Config{
+			Chains: []ChainConfig{
+				{ChainID: "137"},
+				{ChainID: "80001"},
+			},
+			Mempool: &MempoolConfig{DataDir: "data"},
+		}
+		chains := GetChains()
+		require.Len(t, chains, 2)
+		require.Equal(t, &C.Chains[1], GetChain(big.NewInt(80001)))
+		require.Nil(t, GetChain(big.NewInt(1)))
+		address := chains[0].GetEntrypointContractAddress()
+		require.Equal(t, filepath.Join("data", "137", address.Hex()), dataDir(chains[0], address))
+	})
+
+	t.Run("entry points", func(t *testing.T) {
+		C = &Config{
+			Chains: []ChainConfig{{
+				ChainID:                     "137",
+				EntrypointContractAddress:   "0x01",
+				EntrypointContractAddresses: []string{"0x02", "0x01"},
+			}},
+			Mempool: &MempoolConfig{DataDir: "data"},
+		}
+		chain := GetChains()[0]
+		addresses := chain.GetEntrypointContractAddresses()
+		require.Equal(t, []common.Address{common.HexToAddress("0x01"), common.HexToAddress("0x02")}, addresses)
+		require.Equal(t, filepath.Join("data", "137", addresses[0].Hex()), dataDir(chain, addresses[0]))
+		require.Equal(t, filepath.Join("data", "137", addresses[1].Hex()), dataDir(chain, addresses[1]))
+	})
+
+	t.Run("legacy chain with entry points", func(t *testing.T) {
+		C = &Config{
+			Chain: ChainConfig{
+				ChainID:                     "80001",
+				EntrypointContractAddress:   "0x01",
+				EntrypointContractAddresses: []string{"0x02"},
+			},
+			Mempool: &MempoolConfig{DataDir: "data"},
+		}
+		chain := GetChains()[0]
+		for _, address := range chain.GetEntrypointContractAddresses() {
+			require.Equal(t, filepath.Join("data", "80001", address.Hex()), dataDir(chain, address))
+		}
+	})
+
+	t.Run("chain first", func(t *testing.T) {
+		C = &Config{
+			Chain:  ChainConfig{ChainID: "80001"},
+			Chains: []ChainConfig{{ChainID: "137"}},
+		}
+		chains := GetChains()
+		require.Len(t, chains, 2)
+		require.Equal(t, "80001", chains[0].ChainID)
+		require.Empty(t, dataDir(chains[0], chains[0].GetEntrypointContractAddress()))
+	})
+
+	t.Run("chain with chains", func(t *testing.T) {
+		C = &Config{
+			Chain:   ChainConfig{ChainID: "80001"},
+			Chains:  []ChainConfig{{ChainID: "137"}},
+			Mempool: &MempoolConfig{DataDir: "data"},
+		}
+		chains := GetChains()
+		address := chains[0].GetEntrypointContractAddress()
+		require.Equal(t, filepath.Join("data", "80001", address.Hex()), dataDir(chains[0], address))
+		require.Equal(t, filepath.Join("data", "137", address.Hex()), dataDir(chains[1], address))
+	})
+
+	t.Run("hex chain ID", func(t *testing.T) {
+		C = &Config{
+			Chains:  []ChainConfig{{ChainID: "0x13881"}},
+			Mempool: &MempoolConfig{DataDir: "data"},
+		}
+		chain := GetChains()[0]
+		require.EqualValues(t, 80001, chain.GetChainID().Int64())
+		address := chain.GetEntrypointContractAddress()
+		require.Equal(t, filepath.Join("data", "80001", address.Hex()), dataDir(chain, address))
+	})
+
+	t.Run("legacy database in data_dir", func(t *testing.T) {
+		dir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "CURRENT"), []byte("MANIFEST-000001\n"), 0600))
+		C = &Config{
+			Chains:  []ChainConfig{{ChainID: "137"}},
+			Mempool: &MempoolConfig{DataDir: dir},
+		}
+		chain := GetChains()[0]
+		_, err := GetMempoolDataDir(chain, chain.GetEntrypointContractAddress())
+		require.ErrorContains(t, err, "single chain config")
+	})
+
+	t.Run("default entry point database in chain directory", func(t *testing.T) {
+		dir := t.TempDir()
+		require.NoError(t, os.MkdirAll(filepath.Join(dir, "137"), 0700))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "137", "CURRENT"), []byte("MANIFEST-000001\n"), 0600))
+		C = &Config{
+			Chains:  []ChainConfig{{ChainID: "137", EntrypointContractAddress: "0x01"}},
+			Mempool: &MempoolConfig{DataDir: dir},
+		}
+		chain := GetChains()[0]
+		_, err := GetMempoolDataDir(chain, chain.GetEntrypointContractAddress())
+		require.ErrorContains(t, err, filepath.Join(dir, "137", common.HexToAddress("0x01").Hex()))
+	})
+}
+
+func Test_Load(t *testing.T) {
+	write := func(t *testing.T, content string) string {
+		path := filepath.Join(t.TempDir(), "config.json")
+		require.NoError(t, os.WriteFile(path, []byte(content), 0600))
+		return path
+	}
+	secretKey := "4c0883a69102937d6231471b5dbb6204fe5129617082792ae468d01a3f362318"
+
+	t.Run("chains", func(t *testing.T) {
+		C = &Config{}
+		require.NoError(t, Load(write(t, `{"chains": [{"id": "137", "secret_key": "`+secretKey+`"}, {"id": "80001", "secret_key": "`+secretKey+`"}]}`)))
+		require.Len(t, GetChains(), 2)
+	})
+
+	t.Run("chain configured more than once", func(t *testing.T) {
+		C = &Config{}
+		err := Load(write(t, `{"chain": {"id": "137", "secret_key": "`+secretKey+`"}, "chains": [{"id": "137", "secret_key": "`+secretKey+`"}]}`))
+		require.ErrorContains(t, err, "chain 137 configured more than once")
+	})
+
+	t.Run("invalid chain ID", func(t *testing.T) {
+		C = &Config{}
+		err := Load(write(t, `{"chains": [{"id": "mumbai", "secret_key": "`+secretKey+`"}]}`))
+		require.ErrorContains(t, err, "failed to parse chain id")
+	})
+
+	t.Run("invalid secret key", func(t *testing.T) {
+		C = &Config{}
+		err := Load(write(t, `{"chains": [{"id": "137", "secret_key": "0x1234"}]}`))
+		require.ErrorContains(t, err, "invalid bundler config of chain 137")
+	})
+
+	t.Run("no signer", func(t *testing.T) {
+		C = &Config{}
+		err := Load(write(t, `{"chains": [{"id": "137"}]}`))
+		require.ErrorContains(t, err, "no bundler secret key or signer")
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		C = &Config{}
+		require.Error(t, Load(filepath.Join(t.TempDir(), "config.json")))
+	})
+}
@@ -2,14 +2,13 @@ package controller
 
 import (
 	"bundler/abi"
-	"bundler/config"
 	"bundler/eth"
-	"bundler/util"
+	"bundler/mempool"
+	"bundler/router"
 	"context"
 	"encoding/json"
 	"fmt"
 
-	"github.com/aws/aws-lambda-go/events"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/sirupsen/logrus"
 	"golang.org/x/xerrors"
@@ -17,17 +16,66 @@ import (
 
 var (
 	l = logrus.WithField("module", "controller")
+
+	// Entry points without mempool are not in pools.
+	pools = make(map[*eth.EntryPoint]*mempool.Mempool)
+	// Trackers of bundles sent immediately, for entry points without mempool.
+	trackers = make(map[*eth.EntryPoint]*mempool.Tracker)
 )
 
+// UseMempool makes controllers put operations to entrypoint into p instead of sending them immediately.
+func UseMempool(entrypoint *eth.EntryPoint, p *mempool.Mempool) {
+	pools[entrypoint] = p
+}
+
+// UseTracker makes controllers record bundles sent immediately to entrypoint in t.
+func UseTracker(entrypoint *eth.EntryPoint, t *mempool.Tracker) {
+	trackers[entrypoint] = t
+}
+
+// sendHandleOps sends ops immediately, and records the bundle in the tracker of entrypoint if any.
+func sendHandleOps(ctx context.Context, entrypoint *eth.EntryPoint, ops []abi.UserOperation, requestIDs []common.Hash) (txHash string, err error) {
+	tx, err := entrypoint.SendHandleOps(ctx, ops)
+	if err != nil {
+		return "", err
+	}
+	if tracker := trackers[entrypoint]; tracker != nil {
+		if err := tracker.Track(tx, requestIDs, ops); err != nil {
+			l.Errorf("Failed to track bundle %s: %s", tx.Hash().Hex(), err.Error())
+		}
+	}
+	return tx.Hash().Hex(), nil
+}
+
 type HealthResponse struct {
-	Hello                     string `json:"hello"`
+	Hello string `json:"hello"`
+	// Default chain, served without chain ID in URL.
+	ChainHealth
+	// Every configured chain. Only given by `/healthz`.
+	Chains []ChainHealth `json:"chains,omitempty"`
+}
+
+type ChainHealth struct {
 	BundlerEOA                string `json:"bundler_eoa"`
 	ChainID                   string `json:"chain_id"`
 	EntrypointContractAddress string `json:"entrypoint_contract_address"`
+	// Every supported entry point, the first one is `EntrypointContractAddress`.
+	EntrypointContractAddresses []string `json:"entrypoint_contract_addresses"`
+	// Every bundler key, the first one is `BundlerEOA`.
+	Bundlers []BundlerHealth `json:"bundlers"`
+}
+
+type BundlerHealth struct {
+	Address string `json:"address"`
+	// Wei in decimal
+	Balance  string `json:"balance"`
+	InFlight uint64 `json:"in_flight"`
 }
 
 type HandleOpsRequest struct {
 	UserOperations []UserOperation `json:"user_operations"`
+	// Entry point to send operations to. Optional, default to `entrypoint_contract_address`.
+	EntryPoint *string `json:"entry_point"`
 }
 
 type UserOperation struct {
@@ -59,110 +107,368 @@ type UserOperation struct {
 	// No need to give paymaster data
 }
 
+// HexHandleOpsRequest is HandleOpsRequest in ERC-4337 style:
+// camelCase keys, `0x` hex encoded bytes and quantities.
+type HexHandleOpsRequest struct {
+	UserOperations []RPCUserOperation `json:"userOperations"`
+	// Optional, default to `entrypoint_contract_address`.
+	EntryPoint *common.Address `json:"entryPoint"`
+}
+
 type HandleOpsResponse struct {
+	// Empty if operations are put into mempool.
 	TxHash string `json:"tx_hash"`
+	// Request ID of each operation, in request order.
+	RequestIDs []string `json:"request_ids,omitempty"`
+}
+
+// HexHandleOpsResponse is HandleOpsResponse given to HexHandleOpsRequest.
+type HexHandleOpsResponse struct {
+	TxHash     string   `json:"txHash"`
+	RequestIDs []string `json:"requestIds,omitempty"`
 }
 
-func (uo *UserOperation) ToABIStruct() (abiUO abi.UserOperation, err error) {
-	abiUO = abi.UserOperation{}
+// ToABIStruct returns *ValidationError listing every invalid field.
+func (uo *UserOperation) ToABIStruct() (abi.UserOperation, error) {
+	v := newValidator(0)
+	op := uo.validate(v)
+	return op, validationError(v.errors)
+}
 
-	abiUO.Sender = util.ParseAddressString(*uo.Sender)
-	abiUO.Nonce = util.ParseBigIntString(*uo.Nonce)
-	abiUO.CallData, err = util.ParseBase64String(*uo.CallData)
-	abiUO.CallGas = util.ParseBigIntString(*uo.CallGas)
-	if err != nil {
-		return abi.UserOperation{}, xerrors.Errorf("failed to parse call data: %w", err)
+// validate converts uo, reporting field errors to v. Invalid fields are left zero.
+func (uo *UserOperation) validate(v *validator) abi.UserOperation {
+	op := abi.UserOperation{
+		Sender:               v.address("sender", uo.Sender, true),
+		Nonce:                v.decimal("nonce", uo.Nonce, 256, true),
+		InitCode:             v.base64("init_code", uo.InitCode, false),
+		CallData:             v.base64("call_data", uo.CallData, false),
+		CallGas:              v.decimal("call_gas", uo.CallGas, maxGasBits, true),
+		VerificationGas:      v.decimal("verification_gas", uo.VerificationGas, maxGasBits, true),
+		PreVerificationGas:   v.decimal("pre_verification_gas", uo.PreVerificationGas, maxGasBits, true),
+		MaxFeePerGas:         v.decimal("max_fee_per_gas", uo.MaxFeePerGas, maxGasBits, true),
+		MaxPriorityFeePerGas: v.decimal("max_priority_fee_per_gas", uo.MaxPriorityFeePerGas, maxGasBits, true),
+		Paymaster:            v.address("paymaster", uo.Paymaster, false),
+		PaymasterData:        v.base64("paymaster_data", uo.PaymasterData, false),
+		Signature:            v.base64("signature", uo.Signature, true),
 	}
-	abiUO.InitCode, err = util.ParseBase64String(*uo.InitCode)
-	if err != nil {
-		return abi.UserOperation{}, xerrors.Errorf("failed to parse init code: %w", err)
+	v.nonEmpty("signature", op.Signature)
+	return op
+}
+
+type ErrorResponse struct {
+	Message string `json:"message"`
+	// Following fields are only given if EntryPoint rejected an operation with `FailedOp`.
+	// Index of the rejected operation in `user_operations`.
+	OpIndex *int `json:"op_index,omitempty"`
+	// Zero address if wallet rejected the operation.
+	Paymaster *string `json:"paymaster,omitempty"`
+	Reason    *string `json:"reason,omitempty"`
+	// Every invalid field, if the request is invalid.
+	Errors []FieldError `json:"errors,omitempty"`
+}
+
+// HexErrorResponse is ErrorResponse given to HexHandleOpsRequest.
+type HexErrorResponse struct {
+	Message   string       `json:"message"`
+	OpIndex   *int         `json:"opIndex,omitempty"`
+	Paymaster *string      `json:"paymaster,omitempty"`
+	Reason    *string      `json:"reason,omitempty"`
+	Errors    []FieldError `json:"errors,omitempty"`
+}
+
+func errorResp(code int, message string) router.Response {
+	return router.JSON(code, ErrorResponse{Message: message})
+}
+
+// failedOpError returns structured `FailedOp` if err contains one.
+// index is the index of operation in request.
+func failedOpError(index int, message string, err error) ErrorResponse {
+	failedOp := &eth.FailedOpError{}
+	if !xerrors.As(err, &failedOp) {
+		return ErrorResponse{Message: message}
 	}
-	abiUO.VerificationGas = util.ParseBigIntString(*uo.VerificationGas)
-	abiUO.PreVerificationGas = util.ParseBigIntString(*uo.PreVerificationGas)
-	abiUO.MaxFeePerGas = util.ParseBigIntString(*uo.MaxFeePerGas)
-	abiUO.MaxPriorityFeePerGas = util.ParseBigIntString(*uo.MaxPriorityFeePerGas)
-	abiUO.Signature, err = util.ParseBase64String(*uo.Signature)
-	if err != nil {
-		return abi.UserOperation{}, xerrors.Errorf("failed to parse signature: %w", err)
+
+	paymaster := failedOp.Paymaster.Hex()
+	return ErrorResponse{
+		Message:   message,
+		OpIndex:   &index,
+		Paymaster: &paymaster,
+		Reason:    &failedOp.Reason,
 	}
+}
 
-	abiUO.Paymaster = common.HexToAddress(*uo.Paymaster)
-	abiUO.PaymasterData, err = util.ParseBase64String(*uo.PaymasterData)
-	if err != nil {
-		return abi.UserOperation{}, xerrors.Errorf("failed to parse paymaster data: %w", err)
+// handleStyle is the encoding used by a `/handle` client. Responses are given in the same style.
+type handleStyle int
+
+const (
+	// HandleOpsRequest: snake_case keys, base64 encoded bytes and decimal numbers.
+	styleLegacy handleStyle = iota
+	// HexHandleOpsRequest
+	styleHex
+)
+
+func (s handleStyle) errorResp(code int, message string) router.Response {
+	if s == styleHex {
+		return router.JSON(code, HexErrorResponse{Message: message})
 	}
+	return errorResp(code, message)
+}
 
-	return
+// invalidResp lists every field error if err is *ValidationError.
+func (s handleStyle) invalidResp(code int, err error) router.Response {
+	body := ErrorResponse{Message: err.Error()}
+	validationErr := &ValidationError{}
+	if xerrors.As(err, &validationErr) {
+		body.Errors = validationErr.Errors
+	}
+	if s == styleHex {
+		return router.JSON(code, HexErrorResponse(body))
+	}
+	return router.JSON(code, body)
 }
 
-func errorResp(code int, body string) (events.APIGatewayProxyResponse, error) {
-	return events.APIGatewayProxyResponse{
-		StatusCode: code,
-		Headers:    map[string]string{"Content-Type": "application/json"},
-		Body:       fmt.Sprintf("{\"message\": \"%s\"}", body),
-	}, nil
+func (s handleStyle) failedOpResp(code int, index int, message string, err error) router.Response {
+	body := failedOpError(index, message, err)
+	if s == styleHex {
+		return router.JSON(code, HexErrorResponse(body))
+	}
+	return router.JSON(code, body)
 }
 
-func successResp(body any) (events.APIGatewayProxyResponse, error) {
-	bodyBytes, err := json.Marshal(body)
+func (s handleStyle) successResp(txHash string, requestIDs []string) router.Response {
+	if s == styleHex {
+		return successResp(HexHandleOpsResponse{TxHash: txHash, RequestIDs: requestIDs})
+	}
+	return successResp(HandleOpsResponse{TxHash: txHash, RequestIDs: requestIDs})
+}
+
+// parseUserOperation decodes and validates operation #index of HandleOpsRequest.
+// Fields are decoded one by one, so that every invalid field is reported.
+func parseUserOperation(raw json.RawMessage, index int) (abi.UserOperation, []FieldError) {
+	uo := UserOperation{}
+	v := newValidator(index)
+	v.decode(raw, []fieldTarget{
+		{"sender", &uo.Sender},
+		{"nonce", &uo.Nonce},
+		{"init_code", &uo.InitCode},
+		{"call_data", &uo.CallData},
+		{"call_gas", &uo.CallGas},
+		{"verification_gas", &uo.VerificationGas},
+		{"pre_verification_gas", &uo.PreVerificationGas},
+		{"max_fee_per_gas", &uo.MaxFeePerGas},
+		{"max_priority_fee_per_gas", &uo.MaxPriorityFeePerGas},
+		{"paymaster", &uo.Paymaster},
+		{"paymaster_data", &uo.PaymasterData},
+		{"signature", &uo.Signature},
+	})
+	if len(v.errors) > 0 && v.errors[0].Field == "" {
+		// Not an object
+		return abi.UserOperation{}, v.errors
+	}
+	op := uo.validate(v)
+	return op, v.errors
+}
+
+// parseHandleOpsRequest decodes body as HexHandleOpsRequest if it has `userOperations`,
+// as HandleOpsRequest otherwise.
+// Every invalid field of every operation is returned in *ValidationError.
+func parseHandleOpsRequest(body []byte) (handleStyle, []abi.UserOperation, *common.Address, error) {
+	keys := map[string]json.RawMessage{}
+	if err := json.Unmarshal(body, &keys); err != nil {
+		return styleLegacy, nil, nil, xerrors.Errorf("failed to parse request body: %w", err)
+	}
+
+	style := styleLegacy
+	parse := parseUserOperation
+	raws := []json.RawMessage{}
+	var entryPoint *common.Address
+	if _, ok := keys["userOperations"]; ok {
+		style = styleHex
+		parse = func(raw json.RawMessage, index int) (abi.UserOperation, []FieldError) {
+			return parseRPCUserOperation(raw, index, false)
+		}
+		req := struct {
+			UserOperations []json.RawMessage `json:"userOperations"`
+			EntryPoint     *common.Address   `json:"entryPoint"`
+		}{}
+		if err := json.Unmarshal(body, &req); err != nil {
+			return style, nil, nil, xerrors.Errorf("failed to parse request body: %w", err)
+		}
+		raws = req.UserOperations
+		entryPoint = req.EntryPoint
+	} else {
+		req := struct {
+			UserOperations []json.RawMessage `json:"user_operations"`
+			EntryPoint     *string           `json:"entry_point"`
+		}{}
+		if err := json.Unmarshal(body, &req); err != nil {
+			return style, nil, nil, xerrors.Errorf("failed to parse request body: %w", err)
+		}
+		raws = req.UserOperations
+		if req.EntryPoint != nil {
+			if !common.IsHexAddress(*req.EntryPoint) {
+				return style, nil, nil, xerrors.Errorf("invalid entry point: %s", *req.EntryPoint)
+			}
+			address := common.HexToAddress(*req.EntryPoint)
+			entryPoint = &address
+		}
+	}
+
+	ops := make([]abi.UserOperation, 0, len(raws))
+	fieldErrors := []FieldError{}
+	for index, raw := range raws {
+		op, errors := parse(raw, index)
+		ops = append(ops, op)
+		fieldErrors = append(fieldErrors, errors...)
+	}
+	return style, ops, entryPoint, validationError(fieldErrors)
+}
+
+func successResp(body any) router.Response {
+	return router.JSON(200, body)
+}
+
+// ChainHandlerFunc handles a request to one chain.
+type ChainHandlerFunc func(ctx context.Context, chain *eth.Chain, request router.Request) router.Response
+
+func forChain(chain *eth.Chain, handler ChainHandlerFunc) router.HandlerFunc {
+	return func(ctx context.Context, request router.Request) router.Response {
+		return handler(ctx, chain, request)
+	}
+}
+
+// NewRouter registers all bundler API routes.
+// Every chain is served under its chain ID, e.g. `/80001/rpc`,
+// and the first configured chain is also served without chain ID.
+func NewRouter() *router.Router {
+	r := router.New()
+	defaultChain := eth.Chains()[0]
+	r.Handle("/handle", forChain(defaultChain, HandleOps))
+	r.Handle("/", forChain(defaultChain, RPC))
+	r.Handle("/rpc", forChain(defaultChain, RPC))
+	for _, chain := range eth.Chains() {
+		prefix := "/" + chain.ID.String()
+		r.Handle(prefix+"/healthz", forChain(chain, ChainHealthz))
+		r.Handle(prefix+"/handle", forChain(chain, HandleOps))
+		r.Handle(prefix, forChain(chain, RPC))
+		r.Handle(prefix+"/rpc", forChain(chain, RPC))
+	}
+	r.Handle("/healthz", Healthz)
+	return r
+}
+
+func chainHealth(ctx context.Context, chain *eth.Chain) (ChainHealth, error) {
+	statuses, err := chain.BundlerStatuses(ctx)
 	if err != nil {
-		return events.APIGatewayProxyResponse{
-			StatusCode:      500,
-			Headers:         map[string]string{"Content-Type": "application/json"},
-			Body:            fmt.Sprintf("{\"message\": \"failed to marshal response body: %s\"}", err.Error()),
-			IsBase64Encoded: false,
-		}, nil
-	}
-	return events.APIGatewayProxyResponse{
-		StatusCode:      200,
-		Headers:         map[string]string{"Content-Type": "application/json"},
-		Body:            string(bodyBytes),
-		IsBase64Encoded: false,
+		return ChainHealth{}, xerrors.Errorf("failed to get bundler status of chain %s: %w", chain.ID.String(), err)
+	}
+	bundlers := make([]BundlerHealth, 0, len(statuses))
+	for _, status := range statuses {
+		bundlers = append(bundlers, BundlerHealth{
+			Address:  status.Address.Hex(),
+			Balance:  status.Balance.String(),
+			InFlight: status.InFlight,
+		})
+	}
+
+	entrypoints := make([]string, 0, len(chain.EntryPoints))
+	for _, entrypoint := range chain.EntryPoints {
+		entrypoints = append(entrypoints, entrypoint.Address.Hex())
+	}
+
+	return ChainHealth{
+		BundlerEOA:                  chain.Config.GetBundlerAddress().Hex(),
+		ChainID:                     chain.ID.String(),
+		EntrypointContractAddress:   chain.DefaultEntryPoint().Address.Hex(),
+		EntrypointContractAddresses: entrypoints,
+		Bundlers:                    bundlers,
 	}, nil
 }
 
-func Healthz(request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+// Healthz reports every configured chain.
+func Healthz(ctx context.Context, request router.Request) router.Response {
+	chains := make([]ChainHealth, 0, len(eth.Chains()))
+	for _, chain := range eth.Chains() {
+		health, err := chainHealth(ctx, chain)
+		if err != nil {
+			return errorResp(500, err.Error())
+		}
+		chains = append(chains, health)
+	}
+
 	return successResp(HealthResponse{
-		Hello:                     "bundler",
-		BundlerEOA:                config.GetBundlerAddress().Hex(),
-		ChainID:                   config.GetChainID().String(),
-		EntrypointContractAddress: config.GetEntrypointContractAddress().Hex(),
+		Hello:       "bundler",
+		ChainHealth: chains[0],
+		Chains:      chains,
 	})
 }
 
-func HandleOps(request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
-	ctx := context.Background()
-	req := HandleOpsRequest{}
-	err := json.Unmarshal([]byte(request.Body), &req)
+// ChainHealthz reports one chain.
+func ChainHealthz(ctx context.Context, chain *eth.Chain, request router.Request) router.Response {
+	health, err := chainHealth(ctx, chain)
 	if err != nil {
-		return errorResp(400, fmt.Sprintf("failed to parse request body: %s", err.Error()))
+		return errorResp(500, err.Error())
 	}
+	return successResp(HealthResponse{
+		Hello:       "bundler",
+		ChainHealth: health,
+	})
+}
 
-	if len(req.UserOperations) == 0 {
-		return errorResp(400, "no user operations")
+// HandleOps accepts both HandleOpsRequest and HexHandleOpsRequest,
+// and responds in the style of request.
+func HandleOps(ctx context.Context, chain *eth.Chain, request router.Request) router.Response {
+	style, abiUOs, entryPoint, err := parseHandleOpsRequest(request.Body)
+	if err != nil {
+		return style.invalidResp(400, err)
 	}
 
-	abiUOs := make([]abi.UserOperation, 0, len(req.UserOperations))
-	for index, uo := range req.UserOperations {
-		abiUO, err := uo.ToABIStruct()
+	if len(abiUOs) == 0 {
+		return style.errorResp(400, "no user operations")
+	}
+	entrypoint := chain.DefaultEntryPoint()
+	if entryPoint != nil {
+		entrypoint = chain.EntryPoint(*entryPoint)
+		if entrypoint == nil {
+			return style.errorResp(400, fmt.Sprintf("unsupported entry point %s", entryPoint.Hex()))
+		}
+	}
+
+	for index, op := range abiUOs {
+		_, err := simulate(ctx, entrypoint, op)
 		if err != nil {
-			return errorResp(400, fmt.Sprintf("failed to parse user operation #%d: %s", index, err.Error()))
+			return style.failedOpResp(400, index, fmt.Sprintf("failed to simulate user operation #%d: %s", index, err.Error()), err)
 		}
-		abiUOs = append(abiUOs, abiUO)
 	}
+
+	requestIDs := make([]common.Hash, 0, len(abiUOs))
+	hexRequestIDs := make([]string, 0, len(abiUOs))
 	for index, op := range abiUOs {
-		err := eth.Simulate(ctx, op)
+		requestID, err := entrypoint.RequestID(op)
 		if err != nil {
-			return errorResp(400, fmt.Sprintf("failed to simulate user operation #%d: %s", index, err.Error()))
+			return style.errorResp(500, fmt.Sprintf("failed to get request ID of user operation #%d: %s", index, err.Error()))
 		}
+		requestIDs = append(requestIDs, requestID)
+		hexRequestIDs = append(hexRequestIDs, requestID.Hex())
 	}
 
-	txHash, err := eth.HandleOps(ctx, abiUOs)
+	if pool := pools[entrypoint]; pool != nil {
+		// Every operation is checked before any is added, so that a rejected request leaves nothing in mempool.
+		if err := pool.AddAll(requestIDs, abiUOs); err != nil {
+			return style.errorResp(400, fmt.Sprintf("failed to add user operations to mempool: %s", err.Error()))
+		}
+		return style.successResp("", hexRequestIDs)
+	}
+
+	txHash, err := sendHandleOps(ctx, entrypoint, abiUOs, requestIDs)
 	if err != nil {
-		return errorResp(500, fmt.Sprintf("failed to send HandleOps call: %s", err.Error()))
+		message := fmt.Sprintf("failed to send HandleOps call: %s", err.Error())
+		failedOp := &eth.FailedOpError{}
+		if xerrors.As(err, &failedOp) && failedOp.OpIndex.IsInt64() {
+			return style.failedOpResp(500, int(failedOp.OpIndex.Int64()), message, err)
+		}
+		return style.errorResp(500, message)
 	}
 
-	return successResp(HandleOpsResponse{
-		TxHash: txHash,
-	})
+	return style.successResp(txHash, hexRequestIDs)
 }
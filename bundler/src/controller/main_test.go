@@ -0,0 +1,180 @@
+package controller
+
+import (
+	"bundler/config"
+	"bundler/eth"
+	"bundler/router"
+	"context"
+	"encoding/json"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_NewRouter(t *testing.T) {
+	config.C = &config.Config{
+		Chain: config.ChainConfig{
+			ChainID:                   "80001",
+			RPCServer:                 "http://127.0.0.1:1",
+			EntrypointContractAddress: "0x8A42F70047a99298822dD1dbA34b454fc49913F2",
+		},
+		Chains: []config.ChainConfig{{
+			ChainID:                     "137",
+			RPCServer:                   "http://127.0.0.1:2",
+			EntrypointContractAddress:   "0x1000000000000000000000000000000000000001",
+			EntrypointContractAddresses: []string{"0x1000000000000000000000000000000000000002"},
+		}},
+	}
+	eth.Init()
+	r := NewRouter()
+
+	call := func(path string, method string) (int, map[string]any) {
+		resp := r.Serve(context.Background(), router.Request{
+			Path: path,
+			Body: []byte(`{"jsonrpc":"2.0","id":1,"method":"` + method + `"}`),
+		})
+		result := map[string]any{}
+		require.NoError(t, json.Unmarshal(resp.Body, &result))
+		return resp.StatusCode, result
+	}
+
+	t.Run("default chain", func(t *testing.T) {
+		for _, path := range []string{"/", "/rpc", "/80001", "/80001/rpc/"} {
+			code, resp := call(path, "eth_chainId")
+			require.Equal(t, 200, code)
+			require.Equal(t, "0x13881", resp["result"], path)
+		}
+	})
+
+	t.Run("chain by ID", func(t *testing.T) {
+		code, resp := call("/137/rpc", "eth_chainId")
+		require.Equal(t, 200, code)
+		require.Equal(t, "0x89", resp["result"])
+
+		_, resp = call("/137", "eth_supportedEntryPoints")
+		require.Equal(t, []any{
+			"0x1000000000000000000000000000000000000001",
+			"0x1000000000000000000000000000000000000002",
+		}, resp["result"])
+	})
+
+	t.Run("unknown chain", func(t *testing.T) {
+		code, _ := call("/5/rpc", "eth_chainId")
+		require.Equal(t, 404, code)
+	})
+}
+
+func Test_parseHandleOpsRequest(t *testing.T) {
+	legacy := `{"user_operations": [{
+		"sender": "0x7f477B448FA08E8801c7fe44546e6aEae9Daae19",
+		"nonce": "4",
+		"init_code": "",
+		"call_data": "gMXH0A==",
+		"call_gas": "89128",
+		"verification_gas": "103600",
+		"pre_verification_gas": "21000",
+		"max_fee_per_gas": "180000000000",
+		"max_priority_fee_per_gas": "30000000000",
+		"paymaster": "0x0000000000000000000000000000000000000000",
+		"paymaster_data": "",
+		"signature": "4N0="
+	}]}`
+	hex := `{"userOperations": [{
+		"sender": "0x7f477B448FA08E8801c7fe44546e6aEae9Daae19",
+		"nonce": "0x4",
+		"callData": "0x80c5c7d0",
+		"callGas": "0x15c28",
+		"verificationGas": "0x194b0",
+		"preVerificationGas": "0x5208",
+		"maxFeePerGas": "0x29e8d60800",
+		"maxPriorityFeePerGas": "0x6fc23ac00",
+		"signature": "0xe0dd"
+	}], "entryPoint": "0x8A42F70047a99298822dD1dbA34b454fc49913F2"}`
+
+	t.Run("same operation in both styles", func(t *testing.T) {
+		style, legacyOps, entryPoint, err := parseHandleOpsRequest([]byte(legacy))
+		require.NoError(t, err)
+		require.Equal(t, styleLegacy, style)
+		require.Nil(t, entryPoint)
+
+		style, hexOps, entryPoint, err := parseHandleOpsRequest([]byte(hex))
+		require.NoError(t, err)
+		require.Equal(t, styleHex, style)
+		require.Equal(t, common.HexToAddress("0x8A42F70047a99298822dD1dbA34b454fc49913F2"), *entryPoint)
+
+		require.Equal(t, legacyOps, hexOps)
+		require.EqualValues(t, 89128, hexOps[0].CallGas.Int64())
+		require.Equal(t, []byte{0xe0, 0xdd}, hexOps[0].Signature)
+	})
+
+	t.Run("hex missing field", func(t *testing.T) {
+		style, _, _, err := parseHandleOpsRequest([]byte(`{"userOperations": [{"sender": "0x7f477B448FA08E8801c7fe44546e6aEae9Daae19"}]}`))
+		require.Equal(t, styleHex, style)
+		require.ErrorContains(t, err, "user operation #0")
+	})
+
+	t.Run("every invalid field", func(t *testing.T) {
+		_, _, _, err := parseHandleOpsRequest([]byte(`{"user_operations": [{
+			"sender": "0x7f477B448FA08E8801c7fe44546e6aEae9Daae",
+			"nonce": 4,
+			"call_gas": "1329227995784915872903807060280344576",
+			"verification_gas": "103600",
+			"pre_verification_gas": "21000",
+			"max_fee_per_gas": "-1",
+			"max_priority_fee_per_gas": "30000000000",
+			"signature": ""
+		}, "op"]}`))
+		validationErr := &ValidationError{}
+		require.ErrorAs(t, err, &validationErr)
+		require.Len(t, validationErr.Errors, 6)
+		require.Equal(t, "nonce", validationErr.Errors[0].Field)
+		require.Equal(t, []FieldError{
+			{Index: 0, Field: "sender", Message: "should be a 20-byte hex address"},
+			{Index: 0, Field: "call_gas", Message: "should fit in uint120"},
+			{Index: 0, Field: "max_fee_per_gas", Message: "should not be negative"},
+			{Index: 0, Field: "signature", Message: "should not be empty"},
+		}, validationErr.Errors[1:5])
+		require.Equal(t, 1, validationErr.Errors[5].Index)
+		require.Empty(t, validationErr.Errors[5].Field)
+	})
+
+	t.Run("hex overflow", func(t *testing.T) {
+		_, _, _, err := parseHandleOpsRequest([]byte(strings.Replace(hex, `"callGas": "0x15c28"`, `"callGas": "0x1000000000000000000000000000000"`, 1)))
+		require.ErrorContains(t, err, "user operation #0: callGas: should fit in uint120")
+	})
+}
+
+func Test_handleStyle(t *testing.T) {
+	failedOp := &eth.FailedOpError{OpIndex: big.NewInt(0), Reason: "AA23"}
+	parse := func(resp router.Response) map[string]any {
+		result := map[string]any{}
+		require.NoError(t, json.Unmarshal(resp.Body, &result))
+		return result
+	}
+
+	t.Run("legacy", func(t *testing.T) {
+		body := parse(styleLegacy.failedOpResp(400, 1, "failed", failedOp))
+		require.EqualValues(t, 1, body["op_index"])
+		require.Equal(t, "AA23", body["reason"])
+
+		body = parse(styleLegacy.invalidResp(400, validationError([]FieldError{{Index: 0, Field: "nonce", Message: "missing field"}})))
+		require.Equal(t, []any{map[string]any{"index": float64(0), "field": "nonce", "message": "missing field"}}, body["errors"])
+
+		body = parse(styleLegacy.successResp("", []string{"0x01"}))
+		require.Equal(t, []any{"0x01"}, body["request_ids"])
+		require.Contains(t, body, "tx_hash")
+	})
+
+	t.Run("hex", func(t *testing.T) {
+		body := parse(styleHex.failedOpResp(400, 1, "failed", failedOp))
+		require.EqualValues(t, 1, body["opIndex"])
+		require.NotContains(t, body, "op_index")
+
+		body = parse(styleHex.successResp("0x02", nil))
+		require.Equal(t, "0x02", body["txHash"])
+		require.NotContains(t, body, "requestIds")
+	})
+}
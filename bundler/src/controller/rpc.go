@@ -0,0 +1,492 @@
+package controller
+
+import (
+	"bundler/abi"
+	"bundler/eth"
+	"bundler/router"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"golang.org/x/xerrors"
+)
+
+// JSON-RPC 2.0 error codes.
+// -32500 is defined by ERC-4337 for operations rejected by `simulateValidation`.
+const (
+	rpcCodeParseError     = -32700
+	rpcCodeInvalidRequest = -32600
+	rpcCodeMethodNotFound = -32601
+	rpcCodeInvalidParams  = -32602
+	rpcCodeInternalError  = -32603
+	rpcCodeRejectedByEP   = -32500
+	// Banned opcode or storage access during validation.
+	rpcCodeBannedOpcode = -32502
+	// Signature does not match wallet owner.
+	rpcCodeInvalidSignature = -32507
+)
+
+type rpcRequest struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params"`
+}
+
+type rpcResponse struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  any             `json:"result,omitempty"`
+	Error   *rpcError       `json:"error,omitempty"`
+}
+
+// MarshalJSON keeps `"result": null` for successful calls,
+// and omits `result` for failed ones as JSON-RPC 2.0 requires.
+func (r rpcResponse) MarshalJSON() ([]byte, error) {
+	if r.Error != nil {
+		return json.Marshal(struct {
+			JSONRPC string          `json:"jsonrpc"`
+			ID      json.RawMessage `json:"id"`
+			Error   *rpcError       `json:"error"`
+		}{r.JSONRPC, r.ID, r.Error})
+	}
+	return json.Marshal(struct {
+		JSONRPC string          `json:"jsonrpc"`
+		ID      json.RawMessage `json:"id"`
+		Result  any             `json:"result"`
+	}{r.JSONRPC, r.ID, r.Result})
+}
+
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	Data    any    `json:"data,omitempty"`
+}
+
+func (e *rpcError) Error() string {
+	return e.Message
+}
+
+// rejectedByEP reports an operation rejected by EntryPoint.
+// `FailedOp` details are given in error data.
+func rejectedByEP(message string, err error) *rpcError {
+	rpcErr := &rpcError{Code: rpcCodeRejectedByEP, Message: fmt.Sprintf("%s: %s", message, err.Error())}
+	bannedOpcode := &eth.BannedOpcodeError{}
+	storageAccess := &eth.StorageAccessError{}
+	if xerrors.As(err, &bannedOpcode) || xerrors.As(err, &storageAccess) {
+		rpcErr.Code = rpcCodeBannedOpcode
+	}
+	signatureErr := &eth.SignatureError{}
+	if xerrors.As(err, &signatureErr) {
+		rpcErr.Code = rpcCodeInvalidSignature
+	}
+	failedOp := &eth.FailedOpError{}
+	if xerrors.As(err, &failedOp) {
+		rpcErr.Data = RPCFailedOp{
+			OpIndex:   (*hexutil.Big)(failedOp.OpIndex),
+			Paymaster: failedOp.Paymaster,
+			Reason:    failedOp.Reason,
+		}
+	}
+	return rpcErr
+}
+
+func invalidParams(format string, args ...any) *rpcError {
+	return &rpcError{Code: rpcCodeInvalidParams, Message: fmt.Sprintf(format, args...)}
+}
+
+// invalidUserOperation lists every invalid field of *ValidationError in error data.
+func invalidUserOperation(err error) *rpcError {
+	rpcErr := invalidParams("%s", err.Error())
+	validationErr := &ValidationError{}
+	if xerrors.As(err, &validationErr) {
+		rpcErr.Data = validationErr.Errors
+	}
+	return rpcErr
+}
+
+type rpcMethod func(ctx context.Context, chain *eth.Chain, params json.RawMessage) (any, error)
+
+var rpcMethods map[string]rpcMethod
+
+func init() {
+	rpcMethods = map[string]rpcMethod{
+		"eth_chainId":                  rpcChainID,
+		"eth_supportedEntryPoints":     rpcSupportedEntryPoints,
+		"eth_sendUserOperation":        rpcSendUserOperation,
+		"eth_estimateUserOperationGas": rpcEstimateUserOperationGas,
+		"eth_getUserOperationReceipt":  rpcGetUserOperationReceipt,
+		"eth_getUserOperationByHash":   rpcGetUserOperationByHash,
+	}
+}
+
+// RPCUserOperation is the canonical ERC-4337 JSON form of a UserOperation:
+// camelCase keys, `0x` hex encoded bytes and quantities.
+type RPCUserOperation struct {
+	Sender               *common.Address `json:"sender"`
+	Nonce                *hexutil.Big    `json:"nonce"`
+	InitCode             *hexutil.Bytes  `json:"initCode"`
+	CallData             *hexutil.Bytes  `json:"callData"`
+	CallGas              *hexutil.Big    `json:"callGas"`
+	VerificationGas      *hexutil.Big    `json:"verificationGas"`
+	PreVerificationGas   *hexutil.Big    `json:"preVerificationGas"`
+	MaxFeePerGas         *hexutil.Big    `json:"maxFeePerGas"`
+	MaxPriorityFeePerGas *hexutil.Big    `json:"maxPriorityFeePerGas"`
+	Paymaster            *common.Address `json:"paymaster"`
+	PaymasterData        *hexutil.Bytes  `json:"paymasterData"`
+	Signature            *hexutil.Bytes  `json:"signature"`
+}
+
+// ToABIStruct returns *ValidationError listing every missing or invalid field.
+func (uo *RPCUserOperation) ToABIStruct() (abi.UserOperation, error) {
+	v := newValidator(0)
+	op := uo.validate(v, false)
+	return op, validationError(v.errors)
+}
+
+// ToPartialABIStruct fills missing fields (except `sender`) with zero values.
+// Used when the operation is not complete yet, e.g. gas estimation.
+func (uo *RPCUserOperation) ToPartialABIStruct() (abi.UserOperation, error) {
+	v := newValidator(0)
+	op := uo.validate(v, true)
+	return op, validationError(v.errors)
+}
+
+// validate converts uo, reporting field errors to v.
+// Only `sender` is required if partial.
+func (uo *RPCUserOperation) validate(v *validator, partial bool) abi.UserOperation {
+	bytesOrEmpty := func(field string, value *hexutil.Bytes, required bool) []byte {
+		if !v.present(field, value != nil, required) {
+			return []byte{}
+		}
+		return *value
+	}
+
+	op := abi.UserOperation{
+		Nonce:                v.quantity("nonce", uo.Nonce, 256, !partial),
+		InitCode:             bytesOrEmpty("initCode", uo.InitCode, false),
+		CallData:             bytesOrEmpty("callData", uo.CallData, false),
+		CallGas:              v.quantity("callGas", uo.CallGas, maxGasBits, !partial),
+		VerificationGas:      v.quantity("verificationGas", uo.VerificationGas, maxGasBits, !partial),
+		PreVerificationGas:   v.quantity("preVerificationGas", uo.PreVerificationGas, maxGasBits, !partial),
+		MaxFeePerGas:         v.quantity("maxFeePerGas", uo.MaxFeePerGas, maxGasBits, !partial),
+		MaxPriorityFeePerGas: v.quantity("maxPriorityFeePerGas", uo.MaxPriorityFeePerGas, maxGasBits, !partial),
+		PaymasterData:        bytesOrEmpty("paymasterData", uo.PaymasterData, false),
+		Signature:            bytesOrEmpty("signature", uo.Signature, !partial),
+	}
+	if v.present("sender", uo.Sender != nil, true) {
+		op.Sender = *uo.Sender
+	}
+	if uo.Paymaster != nil {
+		op.Paymaster = *uo.Paymaster
+	}
+	if !partial {
+		v.nonEmpty("signature", op.Signature)
+	}
+	return op
+}
+
+// parseRPCUserOperation decodes and validates operation #index of request.
+// Fields are decoded one by one, so that every invalid field is reported.
+func parseRPCUserOperation(raw json.RawMessage, index int, partial bool) (abi.UserOperation, []FieldError) {
+	uo := RPCUserOperation{}
+	v := newValidator(index)
+	v.decode(raw, []fieldTarget{
+		{"sender", &uo.Sender},
+		{"nonce", &uo.Nonce},
+		{"initCode", &uo.InitCode},
+		{"callData", &uo.CallData},
+		{"callGas", &uo.CallGas},
+		{"verificationGas", &uo.VerificationGas},
+		{"preVerificationGas", &uo.PreVerificationGas},
+		{"maxFeePerGas", &uo.MaxFeePerGas},
+		{"maxPriorityFeePerGas", &uo.MaxPriorityFeePerGas},
+		{"paymaster", &uo.Paymaster},
+		{"paymasterData", &uo.PaymasterData},
+		{"signature", &uo.Signature},
+	})
+	if len(v.errors) > 0 && v.errors[0].Field == "" {
+		// Not an object
+		return abi.UserOperation{}, v.errors
+	}
+	op := uo.validate(v, partial)
+	return op, v.errors
+}
+
+func NewRPCUserOperation(op abi.UserOperation) RPCUserOperation {
+	return RPCUserOperation{
+		Sender:               &op.Sender,
+		Nonce:                (*hexutil.Big)(op.Nonce),
+		InitCode:             (*hexutil.Bytes)(&op.InitCode),
+		CallData:             (*hexutil.Bytes)(&op.CallData),
+		CallGas:              (*hexutil.Big)(op.CallGas),
+		VerificationGas:      (*hexutil.Big)(op.VerificationGas),
+		PreVerificationGas:   (*hexutil.Big)(op.PreVerificationGas),
+		MaxFeePerGas:         (*hexutil.Big)(op.MaxFeePerGas),
+		MaxPriorityFeePerGas: (*hexutil.Big)(op.MaxPriorityFeePerGas),
+		Paymaster:            &op.Paymaster,
+		PaymasterData:        (*hexutil.Bytes)(&op.PaymasterData),
+		Signature:            (*hexutil.Bytes)(&op.Signature),
+	}
+}
+
+type RPCFailedOp struct {
+	OpIndex *hexutil.Big `json:"opIndex"`
+	// Zero address if wallet rejected the operation.
+	Paymaster common.Address `json:"paymaster"`
+	Reason    string         `json:"reason"`
+}
+
+type RPCGasEstimate struct {
+	CallGas            *hexutil.Big `json:"callGas"`
+	VerificationGas    *hexutil.Big `json:"verificationGas"`
+	PreVerificationGas *hexutil.Big `json:"preVerificationGas"`
+}
+
+type RPCUserOperationReceipt struct {
+	RequestID      common.Hash    `json:"requestId"`
+	Sender         common.Address `json:"sender"`
+	Paymaster      common.Address `json:"paymaster"`
+	Nonce          *hexutil.Big   `json:"nonce"`
+	ActualGasCost  *hexutil.Big   `json:"actualGasCost"`
+	ActualGasPrice *hexutil.Big   `json:"actualGasPrice"`
+	Success        bool           `json:"success"`
+	// Decoded revert reason of `callData` execution
+	Reason  string         `json:"reason,omitempty"`
+	Receipt *types.Receipt `json:"receipt"`
+}
+
+type RPCIncludedUserOperation struct {
+	UserOperation   RPCUserOperation `json:"userOperation"`
+	EntryPoint      common.Address   `json:"entryPoint"`
+	BlockNumber     hexutil.Uint64   `json:"blockNumber"`
+	BlockHash       common.Hash      `json:"blockHash"`
+	TransactionHash common.Hash      `json:"transactionHash"`
+}
+
+// RPC serves ERC-4337 JSON-RPC 2.0 requests to chain, including batch requests.
+func RPC(ctx context.Context, chain *eth.Chain, request router.Request) router.Response {
+	body := bytes.TrimSpace(request.Body)
+	if len(body) > 0 && body[0] == '[' {
+		reqs := []json.RawMessage{}
+		if err := json.Unmarshal(body, &reqs); err != nil {
+			return successResp(rpcErrorResponse(nil, &rpcError{Code: rpcCodeParseError, Message: err.Error()}))
+		}
+		if len(reqs) == 0 {
+			return successResp(rpcErrorResponse(nil, &rpcError{Code: rpcCodeInvalidRequest, Message: "empty batch"}))
+		}
+		resps := make([]rpcResponse, 0, len(reqs))
+		for _, req := range reqs {
+			resps = append(resps, handleRPC(ctx, chain, req))
+		}
+		return successResp(resps)
+	}
+
+	return successResp(handleRPC(ctx, chain, body))
+}
+
+func handleRPC(ctx context.Context, chain *eth.Chain, body []byte) rpcResponse {
+	req := rpcRequest{}
+	if err := json.Unmarshal(body, &req); err != nil {
+		return rpcErrorResponse(nil, &rpcError{Code: rpcCodeParseError, Message: err.Error()})
+	}
+	if req.JSONRPC != "2.0" || req.Method == "" {
+		return rpcErrorResponse(req.ID, &rpcError{Code: rpcCodeInvalidRequest, Message: "invalid JSON-RPC 2.0 request"})
+	}
+
+	method, ok := rpcMethods[req.Method]
+	if !ok {
+		return rpcErrorResponse(req.ID, &rpcError{Code: rpcCodeMethodNotFound, Message: fmt.Sprintf("method %s not found", req.Method)})
+	}
+
+	result, err := method(ctx, chain, req.Params)
+	if err != nil {
+		rpcErr, ok := err.(*rpcError)
+		if !ok {
+			rpcErr = &rpcError{Code: rpcCodeInternalError, Message: err.Error()}
+		}
+		l.Warnf("JSON-RPC %s failed: %s", req.Method, rpcErr.Message)
+		return rpcErrorResponse(req.ID, rpcErr)
+	}
+
+	return rpcResponse{
+		JSONRPC: "2.0",
+		ID:      req.ID,
+		Result:  result,
+	}
+}
+
+func rpcErrorResponse(id json.RawMessage, err *rpcError) rpcResponse {
+	if id == nil {
+		id = json.RawMessage("null")
+	}
+	return rpcResponse{
+		JSONRPC: "2.0",
+		ID:      id,
+		Error:   err,
+	}
+}
+
+// parseParams decodes positional params into args.
+// The first `required` args must be present.
+func parseParams(params json.RawMessage, required int, args ...any) error {
+	raws := []json.RawMessage{}
+	if len(params) > 0 {
+		if err := json.Unmarshal(params, &raws); err != nil {
+			return invalidParams("params should be an array: %s", err.Error())
+		}
+	}
+	if len(raws) < required {
+		return invalidParams("expected at least %d params, got %d", required, len(raws))
+	}
+	if len(raws) > len(args) {
+		return invalidParams("expected at most %d params, got %d", len(args), len(raws))
+	}
+
+	for index, raw := range raws {
+		if err := json.Unmarshal(raw, args[index]); err != nil {
+			return invalidParams("failed to parse param #%d: %s", index, err.Error())
+		}
+	}
+	return nil
+}
+
+func entryPointOf(chain *eth.Chain, address common.Address) (*eth.EntryPoint, error) {
+	entrypoint := chain.EntryPoint(address)
+	if entrypoint == nil {
+		return nil, invalidParams("unsupported entry point %s", address.Hex())
+	}
+	return entrypoint, nil
+}
+
+func rpcChainID(ctx context.Context, chain *eth.Chain, params json.RawMessage) (any, error) {
+	return (*hexutil.Big)(chain.ID), nil
+}
+
+func rpcSupportedEntryPoints(ctx context.Context, chain *eth.Chain, params json.RawMessage) (any, error) {
+	addresses := make([]string, 0, len(chain.EntryPoints))
+	for _, entrypoint := range chain.EntryPoints {
+		addresses = append(addresses, entrypoint.Address.Hex())
+	}
+	return addresses, nil
+}
+
+func rpcSendUserOperation(ctx context.Context, chain *eth.Chain, params json.RawMessage) (any, error) {
+	raw := json.RawMessage{}
+	entryPoint := common.Address{}
+	if err := parseParams(params, 2, &raw, &entryPoint); err != nil {
+		return nil, err
+	}
+	entrypoint, err := entryPointOf(chain, entryPoint)
+	if err != nil {
+		return nil, err
+	}
+	op, errors := parseRPCUserOperation(raw, 0, false)
+	if err := validationError(errors); err != nil {
+		return nil, invalidUserOperation(err)
+	}
+
+	if _, err := simulate(ctx, entrypoint, op); err != nil {
+		return nil, rejectedByEP("failed to simulate user operation", err)
+	}
+	requestID, err := entrypoint.RequestID(op)
+	if err != nil {
+		return nil, err
+	}
+
+	if pool := pools[entrypoint]; pool != nil {
+		if err := pool.Add(requestID, op); err != nil {
+			return nil, invalidParams("failed to add user operation to mempool: %s", err.Error())
+		}
+		return requestID, nil
+	}
+
+	txHash, err := sendHandleOps(ctx, entrypoint, []abi.UserOperation{op}, []common.Hash{requestID})
+	if err != nil {
+		return nil, xerrors.Errorf("failed to send HandleOps call: %w", err)
+	}
+	l.Infof("User operation %s sent in tx %s", requestID.Hex(), txHash)
+
+	return requestID, nil
+}
+
+func rpcEstimateUserOperationGas(ctx context.Context, chain *eth.Chain, params json.RawMessage) (any, error) {
+	raw := json.RawMessage{}
+	entryPoint := common.Address{}
+	if err := parseParams(params, 2, &raw, &entryPoint); err != nil {
+		return nil, err
+	}
+	entrypoint, err := entryPointOf(chain, entryPoint)
+	if err != nil {
+		return nil, err
+	}
+	op, errors := parseRPCUserOperation(raw, 0, true)
+	if err := validationError(errors); err != nil {
+		return nil, invalidUserOperation(err)
+	}
+
+	estimate, err := entrypoint.EstimateUserOperationGas(ctx, op)
+	if err != nil {
+		return nil, rejectedByEP("failed to estimate user operation gas", err)
+	}
+
+	return RPCGasEstimate{
+		CallGas:            (*hexutil.Big)(estimate.CallGas),
+		VerificationGas:    (*hexutil.Big)(estimate.VerificationGas),
+		PreVerificationGas: (*hexutil.Big)(estimate.PreVerificationGas),
+	}, nil
+}
+
+func rpcGetUserOperationReceipt(ctx context.Context, chain *eth.Chain, params json.RawMessage) (any, error) {
+	requestID := common.Hash{}
+	if err := parseParams(params, 1, &requestID); err != nil {
+		return nil, err
+	}
+
+	receipt, err := chain.GetUserOperationReceipt(ctx, requestID)
+	if err != nil {
+		return nil, err
+	}
+	if receipt == nil {
+		return nil, nil
+	}
+
+	return RPCUserOperationReceipt{
+		RequestID:      receipt.RequestID,
+		Sender:         receipt.Sender,
+		Paymaster:      receipt.Paymaster,
+		Nonce:          (*hexutil.Big)(receipt.Nonce),
+		ActualGasCost:  (*hexutil.Big)(receipt.ActualGasCost),
+		ActualGasPrice: (*hexutil.Big)(receipt.ActualGasPrice),
+		Success:        receipt.Success,
+		Reason:         receipt.Reason,
+		Receipt:        receipt.Receipt,
+	}, nil
+}
+
+func rpcGetUserOperationByHash(ctx context.Context, chain *eth.Chain, params json.RawMessage) (any, error) {
+	requestID := common.Hash{}
+	if err := parseParams(params, 1, &requestID); err != nil {
+		return nil, err
+	}
+
+	included, err := chain.GetUserOperationByRequestID(ctx, requestID)
+	if err != nil {
+		return nil, err
+	}
+	if included == nil {
+		return nil, nil
+	}
+
+	return RPCIncludedUserOperation{
+		UserOperation:   NewRPCUserOperation(included.UserOperation),
+		EntryPoint:      included.EntryPoint,
+		BlockNumber:     hexutil.Uint64(included.BlockNumber),
+		BlockHash:       included.BlockHash,
+		TransactionHash: included.TransactionHash,
+	}, nil
+}
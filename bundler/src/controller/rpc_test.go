@@ -0,0 +1,223 @@
+package controller
+
+import (
+	"bundler/abi"
+	"bundler/config"
+	"bundler/eth"
+	"bundler/router"
+	"context"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http/httptest"
+	"testing"
+
+	gethabi "github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeNode answers `simulateValidation` calls like EntryPoint, reverting if validation runs out of `verificationGas`.
+type fakeNode struct {
+	t *testing.T
+	// Operations given to `simulateValidation`.
+	simulated []abi.UserOperation
+}
+
+const fakeBlockGasLimit = 30000000
+
+func (n *fakeNode) GetBlockByNumber(number string, full bool) *types.Header {
+	return &types.Header{Number: big.NewInt(1), Difficulty: big.NewInt(0), GasLimit: fakeBlockGasLimit, BaseFee: big.NewInt(100)}
+}
+
+func (n *fakeNode) Call(args map[string]any, block string) (hexutil.Bytes, error) {
+	entrypointABI, err := abi.EntryPointMetaData.GetAbi()
+	require.NoError(n.t, err)
+	data, err := hexutil.Decode(args["data"].(string))
+	require.NoError(n.t, err)
+	method := entrypointABI.Methods["simulateValidation"]
+	require.Equal(n.t, method.ID, data[:4])
+	values, err := method.Inputs.Unpack(data[4:])
+	require.NoError(n.t, err)
+	op := *gethabi.ConvertType(values[0], new(abi.UserOperation)).(*abi.UserOperation)
+	n.simulated = append(n.simulated, op)
+
+	preOpGas := big.NewInt(0).Add(op.PreVerificationGas, big.NewInt(60000))
+	if op.VerificationGas.Cmp(big.NewInt(60000)) < 0 {
+		return nil, errors.New("execution reverted")
+	}
+	return method.Outputs.Pack(preOpGas, big.NewInt(0))
+}
+
+func (n *fakeNode) EstimateGas(args map[string]any) hexutil.Uint64 {
+	return 30000
+}
+
+// before_each_with_node returns a chain connected to a fakeNode.
+func before_each_with_node(t *testing.T) (*eth.Chain, *fakeNode) {
+	node := &fakeNode{t: t}
+	server := rpc.NewServer()
+	require.NoError(t, server.RegisterName("eth", node))
+	httpServer := httptest.NewServer(server)
+	t.Cleanup(httpServer.Close)
+
+	chain := before_each(t)
+	chain.Config.RPCServer = httpServer.URL
+	chain, err := eth.NewChain(chain.Config)
+	require.NoError(t, err)
+	return chain, node
+}
+
+func before_each(t *testing.T) *eth.Chain {
+	config.C = &config.Config{
+		Chain: config.ChainConfig{
+			ChainID: "80001",
+			// Not connected until called.
+			RPCServer:                 "http://127.0.0.1:1",
+			EntrypointContractAddress: "0x8A42F70047a99298822dD1dbA34b454fc49913F2",
+		},
+	}
+	chain, err := eth.NewChain(&config.C.Chain)
+	require.NoError(t, err)
+	return chain
+}
+
+func callRPC(t *testing.T, chain *eth.Chain, body string) map[string]any {
+	resp := RPC(context.Background(), chain, router.Request{Path: "/rpc", Body: []byte(body)})
+	require.Equal(t, 200, resp.StatusCode)
+	result := map[string]any{}
+	require.NoError(t, json.Unmarshal(resp.Body, &result))
+	return result
+}
+
+func Test_RPC(t *testing.T) {
+	t.Run("eth_chainId", func(t *testing.T) {
+		chain := before_each(t)
+
+		resp := callRPC(t, chain, `{"jsonrpc":"2.0","id":1,"method":"eth_chainId","params":[]}`)
+		require.Equal(t, "0x13881", resp["result"])
+		require.EqualValues(t, 1, resp["id"])
+	})
+
+	t.Run("eth_supportedEntryPoints", func(t *testing.T) {
+		chain := before_each(t)
+
+		resp := callRPC(t, chain, `{"jsonrpc":"2.0","id":"a","method":"eth_supportedEntryPoints"}`)
+		require.Equal(t, []any{"0x8A42F70047a99298822dD1dbA34b454fc49913F2"}, resp["result"])
+	})
+
+	t.Run("unsupported entry point", func(t *testing.T) {
+		chain := before_each(t)
+
+		resp := callRPC(t, chain, `{"jsonrpc":"2.0","id":1,"method":"eth_sendUserOperation","params":[{}, "0x0000000000000000000000000000000000000001"]}`)
+		require.EqualValues(t, rpcCodeInvalidParams, resp["error"].(map[string]any)["code"])
+	})
+
+	t.Run("invalid user operation", func(t *testing.T) {
+		chain := before_each(t)
+
+		resp := callRPC(t, chain, `{"jsonrpc":"2.0","id":1,"method":"eth_sendUserOperation","params":[{"sender": "0x01", "nonce": "4"}, "0x8A42F70047a99298822dD1dbA34b454fc49913F2"]}`)
+		rpcErr := resp["error"].(map[string]any)
+		require.EqualValues(t, rpcCodeInvalidParams, rpcErr["code"])
+		fields := []any{}
+		for _, fieldErr := range rpcErr["data"].([]any) {
+			fields = append(fields, fieldErr.(map[string]any)["field"])
+		}
+		require.Equal(t, []any{"sender", "nonce", "callGas", "verificationGas", "preVerificationGas", "maxFeePerGas", "maxPriorityFeePerGas", "signature"}, fields)
+	})
+
+	t.Run("method not found", func(t *testing.T) {
+		chain := before_each(t)
+
+		resp := callRPC(t, chain, `{"jsonrpc":"2.0","id":1,"method":"eth_nothing"}`)
+		require.EqualValues(t, rpcCodeMethodNotFound, resp["error"].(map[string]any)["code"])
+	})
+
+	t.Run("parse error", func(t *testing.T) {
+		chain := before_each(t)
+
+		resp := callRPC(t, chain, `{"jsonrpc":`)
+		require.EqualValues(t, rpcCodeParseError, resp["error"].(map[string]any)["code"])
+		require.Nil(t, resp["id"])
+	})
+
+	t.Run("batch", func(t *testing.T) {
+		chain := before_each(t)
+
+		resp := RPC(context.Background(), chain, router.Request{Path: "/rpc", Body: []byte(`[
+			{"jsonrpc":"2.0","id":1,"method":"eth_chainId"},
+			{"jsonrpc":"2.0","id":2,"method":"eth_nothing"}
+		]`)})
+		results := []map[string]any{}
+		require.NoError(t, json.Unmarshal(resp.Body, &results))
+		require.Len(t, results, 2)
+		require.Equal(t, "0x13881", results[0]["result"])
+		require.NotNil(t, results[1]["error"])
+	})
+}
+
+func Test_RPCEstimateUserOperationGas(t *testing.T) {
+	t.Run("no gas fields", func(t *testing.T) {
+		chain, node := before_each_with_node(t)
+
+		resp := callRPC(t, chain, `{"jsonrpc":"2.0","id":1,"method":"eth_estimateUserOperationGas","params":[
+			{"sender": "0x7f477B448FA08E8801c7fe44546e6aEae9Daae19", "nonce": "0x4", "callData": "0x80c5c7d0", "signature": "0xe0dd"},
+			"0x8A42F70047a99298822dD1dbA34b454fc49913F2"
+		]}`)
+		require.Nil(t, resp["error"])
+		result := resp["result"].(map[string]any)
+		require.Equal(t, "0xea60", result["verificationGas"])
+		require.Equal(t, "0x7530", result["callGas"])
+
+		require.Len(t, node.simulated, 1)
+		require.EqualValues(t, fakeBlockGasLimit, node.simulated[0].VerificationGas.Int64())
+		require.EqualValues(t, fakeBlockGasLimit, node.simulated[0].CallGas.Int64())
+	})
+
+	t.Run("gas fields given", func(t *testing.T) {
+		chain, node := before_each_with_node(t)
+
+		resp := callRPC(t, chain, `{"jsonrpc":"2.0","id":1,"method":"eth_estimateUserOperationGas","params":[
+			{"sender": "0x7f477B448FA08E8801c7fe44546e6aEae9Daae19", "callGas": "0x1", "verificationGas": "0x186a0", "signature": "0xe0dd"},
+			"0x8A42F70047a99298822dD1dbA34b454fc49913F2"
+		]}`)
+		require.Nil(t, resp["error"])
+		require.Len(t, node.simulated, 1)
+		require.EqualValues(t, 100000, node.simulated[0].VerificationGas.Int64())
+		require.EqualValues(t, 1, node.simulated[0].CallGas.Int64())
+	})
+}
+
+func Test_RPCUserOperation(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		uo := RPCUserOperation{}
+		require.NoError(t, json.Unmarshal([]byte(`{
+			"sender": "0x7f477B448FA08E8801c7fe44546e6aEae9Daae19",
+			"nonce": "0x4",
+			"callData": "0x80c5c7d0",
+			"callGas": "0x15c28",
+			"verificationGas": "0x194b0",
+			"preVerificationGas": "0x5208",
+			"maxFeePerGas": "0x29e8d60800",
+			"maxPriorityFeePerGas": "0x6fc23ac00",
+			"signature": "0xe0dd"
+		}`), &uo))
+
+		op, err := uo.ToABIStruct()
+		require.NoError(t, err)
+		require.EqualValues(t, 4, op.Nonce.Int64())
+		require.EqualValues(t, 21000, op.PreVerificationGas.Int64())
+		require.Equal(t, []byte{0x80, 0xc5, 0xc7, 0xd0}, op.CallData)
+		require.Empty(t, op.InitCode)
+		require.Empty(t, op.PaymasterData)
+	})
+
+	t.Run("missing field", func(t *testing.T) {
+		uo := RPCUserOperation{}
+		require.NoError(t, json.Unmarshal([]byte(`{"sender": "0x7f477B448FA08E8801c7fe44546e6aEae9Daae19"}`), &uo))
+		_, err := uo.ToABIStruct()
+		require.ErrorContains(t, err, "nonce")
+	})
+}
@@ -0,0 +1,40 @@
+package controller
+
+import (
+	"bundler/abi"
+	"bundler/eth"
+	"context"
+	"math/big"
+
+	"golang.org/x/xerrors"
+)
+
+// simulate checks wallet signature off-chain, runs `simulateValidation`, then makes sure the operation
+// gives enough verification gas and pays at least current base fee.
+func simulate(ctx context.Context, entrypoint *eth.EntryPoint, op abi.UserOperation) (eth.SimulateResult, error) {
+	if err := entrypoint.CheckWalletSignature(ctx, op); err != nil {
+		return eth.SimulateResult{}, err
+	}
+
+	result, err := entrypoint.Simulate(ctx, op)
+	if err != nil {
+		return result, err
+	}
+
+	// `preOpGas` includes `preVerificationGas`.
+	validationGas := big.NewInt(0).Sub(result.PreOpGas, op.PreVerificationGas)
+	if op.VerificationGas.Cmp(validationGas) < 0 {
+		return result, xerrors.Errorf("verification gas too low: %s given, %s used in simulation", op.VerificationGas.String(), validationGas.String())
+	}
+
+	baseFee, err := entrypoint.BaseFee(ctx)
+	if err != nil {
+		return result, err
+	}
+	// Bundler would pay more than it gets refunded.
+	if baseFee != nil && op.MaxFeePerGas.Cmp(baseFee) < 0 {
+		return result, xerrors.Errorf("max fee per gas too low: %s given, current base fee is %s", op.MaxFeePerGas.String(), baseFee.String())
+	}
+
+	return result, nil
+}
@@ -0,0 +1,165 @@
+package controller
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// `_validatePrepayment` of EntryPoint rejects gas values above uint120 with "gas values overflow".
+const maxGasBits = 120
+
+// FieldError is an invalid field of a user operation in request.
+type FieldError struct {
+	// Index of the operation in request.
+	Index int `json:"index"`
+	// Key of the field as given in request, empty if the operation itself is invalid.
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// ValidationError lists every invalid field of user operations in a request.
+type ValidationError struct {
+	Errors []FieldError
+}
+
+func (e *ValidationError) Error() string {
+	messages := make([]string, 0, len(e.Errors))
+	for _, fieldErr := range e.Errors {
+		if fieldErr.Field == "" {
+			messages = append(messages, fmt.Sprintf("user operation #%d: %s", fieldErr.Index, fieldErr.Message))
+			continue
+		}
+		messages = append(messages, fmt.Sprintf("user operation #%d: %s: %s", fieldErr.Index, fieldErr.Field, fieldErr.Message))
+	}
+	return "invalid user operations: " + strings.Join(messages, "; ")
+}
+
+// validationError returns nil if there is no field error.
+func validationError(errors []FieldError) error {
+	if len(errors) == 0 {
+		return nil
+	}
+	return &ValidationError{Errors: errors}
+}
+
+// validator collects field errors of one user operation.
+type validator struct {
+	index  int
+	errors []FieldError
+	// Fields already reported, so that a field failing to decode is not reported missing again.
+	failed map[string]bool
+}
+
+func newValidator(index int) *validator {
+	return &validator{index: index, failed: make(map[string]bool)}
+}
+
+func (v *validator) fail(field string, format string, args ...any) {
+	v.failed[field] = true
+	v.errors = append(v.errors, FieldError{Index: v.index, Field: field, Message: fmt.Sprintf(format, args...)})
+}
+
+// present reports field missing if it is required but not given.
+// A field failed to decode is not present.
+func (v *validator) present(field string, given bool, required bool) bool {
+	if v.failed[field] {
+		return false
+	}
+	if !given && required {
+		v.fail(field, "missing field")
+	}
+	return given
+}
+
+// checkBits makes sure value fits in an unsigned integer of bits.
+func (v *validator) checkBits(field string, value *big.Int, bits int) {
+	if value.Sign() < 0 {
+		v.fail(field, "should not be negative")
+	} else if value.BitLen() > bits {
+		v.fail(field, "should fit in uint%d", bits)
+	}
+}
+
+// decimal parses a decimal string into an unsigned integer of bits. Zero is returned if invalid.
+func (v *validator) decimal(field string, value *string, bits int, required bool) *big.Int {
+	if !v.present(field, value != nil, required) {
+		return big.NewInt(0)
+	}
+	result, ok := big.NewInt(0).SetString(*value, 10)
+	if !ok {
+		v.fail(field, "should be a decimal number")
+		return big.NewInt(0)
+	}
+	v.checkBits(field, result, bits)
+	return result
+}
+
+// quantity checks a decoded hex quantity. Zero is returned if not given.
+func (v *validator) quantity(field string, value *hexutil.Big, bits int, required bool) *big.Int {
+	if !v.present(field, value != nil, required) {
+		return big.NewInt(0)
+	}
+	v.checkBits(field, value.ToInt(), bits)
+	return value.ToInt()
+}
+
+// address parses a 20-byte hex address string.
+func (v *validator) address(field string, value *string, required bool) common.Address {
+	if !v.present(field, value != nil, required) {
+		return common.Address{}
+	}
+	if !common.IsHexAddress(*value) {
+		v.fail(field, "should be a 20-byte hex address")
+		return common.Address{}
+	}
+	return common.HexToAddress(*value)
+}
+
+// base64 decodes a Base64 string. Empty bytes are returned if not given.
+func (v *validator) base64(field string, value *string, required bool) []byte {
+	if !v.present(field, value != nil, required) {
+		return []byte{}
+	}
+	result, err := base64.StdEncoding.DecodeString(*value)
+	if err != nil {
+		v.fail(field, "should be Base64 encoded: %s", err.Error())
+		return []byte{}
+	}
+	return result
+}
+
+// nonEmpty makes sure a bytes field is not empty.
+func (v *validator) nonEmpty(field string, value []byte) {
+	if len(value) == 0 && !v.failed[field] {
+		v.fail(field, "should not be empty")
+	}
+}
+
+type fieldTarget struct {
+	field  string
+	target any
+}
+
+// decode unmarshals every field of raw into its target separately, so that all invalid fields are reported.
+func (v *validator) decode(raw json.RawMessage, targets []fieldTarget) {
+	fields := map[string]json.RawMessage{}
+	if err := json.Unmarshal(raw, &fields); err != nil {
+		v.errors = append(v.errors, FieldError{Index: v.index, Message: fmt.Sprintf("should be an object: %s", err.Error())})
+		return
+	}
+	for _, t := range targets {
+		value, ok := fields[t.field]
+		if !ok || string(value) == "null" {
+			continue
+		}
+		if err := json.Unmarshal(value, t.target); err != nil {
+			v.fail(t.field, "%s", err.Error())
+		}
+	}
+}
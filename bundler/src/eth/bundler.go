@@ -0,0 +1,127 @@
+package eth
+
+import (
+	"context"
+	"math/big"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/common"
+	"golang.org/x/xerrors"
+
+	"bundler/signer"
+)
+
+type BundlerStatus struct {
+	Address common.Address
+	Balance *big.Int
+	// Transactions sent but not mined yet, plus nonces being sent by this process.
+	InFlight uint64
+}
+
+// BundlerStatuses returns balance and in-flight count of every bundler key.
+func (c *Chain) BundlerStatuses(ctx context.Context) ([]BundlerStatus, error) {
+	addresses := c.Config.GetBundlerAddresses()
+	statuses := make([]BundlerStatus, 0, len(addresses))
+	for _, address := range addresses {
+		balance, err := c.client.BalanceAt(ctx, address, nil)
+		if err != nil {
+			return nil, xerrors.Errorf("failed to get balance of %s: %w", address.Hex(), err)
+		}
+		pending, err := c.client.PendingNonceAt(ctx, address)
+		if err != nil {
+			return nil, xerrors.Errorf("failed to get pending nonce of %s: %w", address.Hex(), err)
+		}
+		mined, err := c.client.NonceAt(ctx, address, nil)
+		if err != nil {
+			return nil, xerrors.Errorf("failed to get nonce of %s: %w", address.Hex(), err)
+		}
+
+		inFlight := uint64(c.nonceManagerOf(address).InFlight())
+		if pending > mined {
+			inFlight += pending - mined
+		}
+		statuses = append(statuses, BundlerStatus{
+			Address:  address,
+			Balance:  balance,
+			InFlight: inFlight,
+		})
+	}
+	return statuses, nil
+}
+
+// selectBundler picks the bundler with fewest transactions in flight among those able to pay cost.
+// Ties are broken by higher balance.
+func selectBundler(statuses []BundlerStatus, cost *big.Int) (common.Address, error) {
+	candidates := make([]BundlerStatus, 0, len(statuses))
+	for _, status := range statuses {
+		if status.Balance.Cmp(cost) >= 0 {
+			candidates = append(candidates, status)
+		}
+	}
+	if len(candidates) == 0 {
+		return common.Address{}, xerrors.Errorf("no bundler has enough balance to pay %s wei", cost.String())
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		if candidates[i].InFlight != candidates[j].InFlight {
+			return candidates[i].InFlight < candidates[j].InFlight
+		}
+		return candidates[i].Balance.Cmp(candidates[j].Balance) > 0
+	})
+	return candidates[0].Address, nil
+}
+
+// bundlerSigner returns the configured signer of a bundler address.
+func (c *Chain) bundlerSigner(address common.Address) (signer.Signer, error) {
+	s := c.Config.GetSigner(address)
+	if s == nil {
+		return nil, xerrors.Errorf("%s is not a bundler", address.Hex())
+	}
+	return s, nil
+}
+
+// maxGasPrice returns the highest gas price a new transaction may pay.
+// Same as default fee cap set by `bind.TransactOpts`, i.e. twice the base fee plus tip.
+func (c *Chain) maxGasPrice(ctx context.Context) (*big.Int, error) {
+	baseFee, err := c.BaseFee(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if baseFee == nil {
+		gasPrice, err := c.client.SuggestGasPrice(ctx)
+		if err != nil {
+			return nil, xerrors.Errorf("failed to suggest gas price: %w", err)
+		}
+		return gasPrice, nil
+	}
+
+	tip, err := c.client.SuggestGasTipCap(ctx)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to suggest gas tip cap: %w", err)
+	}
+	price := big.NewInt(0).Mul(baseFee, big.NewInt(2))
+	return price.Add(price, tip), nil
+}
+
+// SelectBundler picks a bundler signer to send a transaction using up to gasLimit.
+func (c *Chain) SelectBundler(ctx context.Context, gasLimit uint64) (signer.Signer, error) {
+	addresses := c.Config.GetBundlerAddresses()
+	if len(addresses) == 1 {
+		return c.bundlerSigner(addresses[0])
+	}
+
+	price, err := c.maxGasPrice(ctx)
+	if err != nil {
+		return nil, err
+	}
+	statuses, err := c.BundlerStatuses(ctx)
+	if err != nil {
+		return nil, err
+	}
+	cost := big.NewInt(0).Mul(price, big.NewInt(0).SetUint64(gasLimit))
+	address, err := selectBundler(statuses, cost)
+	if err != nil {
+		return nil, err
+	}
+	return c.bundlerSigner(address)
+}
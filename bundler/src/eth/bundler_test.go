@@ -0,0 +1,35 @@
+package eth
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_selectBundler(t *testing.T) {
+	a, b, c := common.HexToAddress("0x0a"), common.HexToAddress("0x0b"), common.HexToAddress("0x0c")
+
+	t.Run("fewest in flight", func(t *testing.T) {
+		address, err := selectBundler([]BundlerStatus{
+			{Address: a, Balance: big.NewInt(1000), InFlight: 2},
+			{Address: b, Balance: big.NewInt(100), InFlight: 1},
+			{Address: c, Balance: big.NewInt(1000), InFlight: 1},
+		}, big.NewInt(100))
+		require.NoError(t, err)
+		require.Equal(t, c, address)
+	})
+
+	t.Run("not enough balance", func(t *testing.T) {
+		address, err := selectBundler([]BundlerStatus{
+			{Address: a, Balance: big.NewInt(1000), InFlight: 3},
+			{Address: b, Balance: big.NewInt(99), InFlight: 0},
+		}, big.NewInt(100))
+		require.NoError(t, err)
+		require.Equal(t, a, address)
+
+		_, err = selectBundler([]BundlerStatus{{Address: b, Balance: big.NewInt(99)}}, big.NewInt(100))
+		require.Error(t, err)
+	})
+}
@@ -0,0 +1,91 @@
+package eth
+
+import (
+	"bytes"
+	"fmt"
+	"math/big"
+
+	gethabi "github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+	"golang.org/x/xerrors"
+
+	"bundler/abi"
+)
+
+// FailedOpError is `FailedOp(uint256 opIndex, address paymaster, string reason)`
+// reverted by EntryPoint contract.
+// Paymaster is zero if wallet rejected the operation.
+type FailedOpError struct {
+	OpIndex   *big.Int
+	Paymaster common.Address
+	Reason    string
+}
+
+func (e *FailedOpError) Error() string {
+	if e.Paymaster == (common.Address{}) {
+		return fmt.Sprintf("FailedOp(opIndex=%s): wallet rejected: %s", e.OpIndex.String(), e.Reason)
+	}
+	return fmt.Sprintf("FailedOp(opIndex=%s): paymaster %s rejected: %s", e.OpIndex.String(), e.Paymaster.Hex(), e.Reason)
+}
+
+// RevertError is `Error(string)` reverted by any contract.
+type RevertError struct {
+	Reason string
+}
+
+func (e *RevertError) Error() string {
+	return fmt.Sprintf("execution reverted: %s", e.Reason)
+}
+
+// revertData extracts revert data from an RPC error, if any.
+func revertData(err error) []byte {
+	var dataErr rpc.DataError
+	if !xerrors.As(err, &dataErr) {
+		return nil
+	}
+	hexData, ok := dataErr.ErrorData().(string)
+	if !ok {
+		return nil
+	}
+	data, decodeErr := hexutil.Decode(hexData)
+	if decodeErr != nil {
+		return nil
+	}
+	return data
+}
+
+// decodeRevertError turns revert data in err into *FailedOpError or *RevertError.
+// err is returned as-is if no known revert data is found.
+func decodeRevertError(err error) error {
+	if err == nil {
+		return nil
+	}
+	data := revertData(err)
+	if len(data) < 4 {
+		return err
+	}
+
+	entrypointABI, abiErr := abi.EntryPointMetaData.GetAbi()
+	if abiErr != nil {
+		return err
+	}
+	failedOp := entrypointABI.Errors["FailedOp"]
+	if bytes.Equal(data[:4], failedOp.ID[:4]) {
+		args, unpackErr := failedOp.Inputs.Unpack(data[4:])
+		if unpackErr != nil {
+			return err
+		}
+		return &FailedOpError{
+			OpIndex:   args[0].(*big.Int),
+			Paymaster: args[1].(common.Address),
+			Reason:    args[2].(string),
+		}
+	}
+
+	if reason, unpackErr := gethabi.UnpackRevert(data); unpackErr == nil {
+		return &RevertError{Reason: reason}
+	}
+	return err
+}
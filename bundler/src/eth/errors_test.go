@@ -0,0 +1,58 @@
+package eth
+
+import (
+	"bundler/abi"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/xerrors"
+)
+
+type dataError struct {
+	data string
+}
+
+func (e *dataError) Error() string {
+	return "execution reverted"
+}
+
+func (e *dataError) ErrorData() interface{} {
+	return e.data
+}
+
+func Test_decodeRevertError(t *testing.T) {
+	t.Run("FailedOp", func(t *testing.T) {
+		entrypointABI, err := abi.EntryPointMetaData.GetAbi()
+		require.NoError(t, err)
+		failedOp := entrypointABI.Errors["FailedOp"]
+		args, err := failedOp.Inputs.Pack(big.NewInt(0), common.HexToAddress("0x0a"), "paymaster deposit too low")
+		require.NoError(t, err)
+		data := append(append([]byte{}, failedOp.ID[:4]...), args...)
+
+		err = decodeRevertError(xerrors.Errorf("wrapped: %w", &dataError{data: hexutil.Encode(data)}))
+		failedOpErr := &FailedOpError{}
+		require.ErrorAs(t, err, &failedOpErr)
+		require.EqualValues(t, 0, failedOpErr.OpIndex.Int64())
+		require.Equal(t, common.HexToAddress("0x0a"), failedOpErr.Paymaster)
+		require.Equal(t, "paymaster deposit too low", failedOpErr.Reason)
+	})
+
+	t.Run("Error(string)", func(t *testing.T) {
+		data := "0x08c379a0" +
+			"0000000000000000000000000000000000000000000000000000000000000020" +
+			"0000000000000000000000000000000000000000000000000000000000000017" +
+			"77616c6c65743a2077726f6e67207369676e6174757265000000000000000000"
+		err := decodeRevertError(&dataError{data: data})
+		revertErr := &RevertError{}
+		require.ErrorAs(t, err, &revertErr)
+		require.Equal(t, "wallet: wrong signature", revertErr.Reason)
+	})
+
+	t.Run("no data", func(t *testing.T) {
+		original := xerrors.New("connection refused")
+		require.Equal(t, original, decodeRevertError(original))
+	})
+}
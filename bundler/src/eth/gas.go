@@ -0,0 +1,201 @@
+package eth
+
+import (
+	"context"
+	"math/big"
+
+	ethereum "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"golang.org/x/xerrors"
+
+	"bundler/abi"
+)
+
+// Overhead used to calculate `preVerificationGas`.
+// Same values as eth-infinitism reference bundler.
+const (
+	// Per-transaction overhead, shared by all operations in one bundle.
+	pvgFixed = 21000
+	// Per-operation overhead in `handleOps` loop.
+	pvgPerUserOp = 18300
+	// Per-word overhead of copying the operation in `handleOps`.
+	pvgPerUserOpWord = 4
+	pvgZeroByte      = 4
+	pvgNonZeroByte   = 16
+	// Expected bundle size.
+	pvgBundleSize = 1
+	// Dummy signature length, used when signature is not provided yet.
+	pvgSigSize = 65
+)
+
+// maxGasValue is the largest gas field accepted by `_validatePrepayment` of EntryPoint.
+var maxGasValue = big.NewInt(0).Sub(big.NewInt(0).Lsh(big.NewInt(1), 120), big.NewInt(1))
+
+type GasEstimate struct {
+	CallGas            *big.Int
+	VerificationGas    *big.Int
+	PreVerificationGas *big.Int
+}
+
+// packUserOperation ABI-encodes op the same way as `packUserOp(op, false)` in `test/utils.ts`.
+func packUserOperation(op abi.UserOperation) ([]byte, error) {
+	entrypointABI, err := abi.EntryPointMetaData.GetAbi()
+	if err != nil {
+		return nil, err
+	}
+	packed, err := entrypointABI.Methods["getRequestId"].Inputs.Pack(op)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to pack user operation: %w", err)
+	}
+	// Strip tuple offset
+	return packed[32:], nil
+}
+
+// CalcPreVerificationGas calculates gas spent on calldata and bundle overhead,
+// which cannot be measured by `simulateValidation`.
+func CalcPreVerificationGas(op abi.UserOperation) (*big.Int, error) {
+	if len(op.Signature) == 0 {
+		op.Signature = make([]byte, pvgSigSize)
+		for i := range op.Signature {
+			op.Signature[i] = 1
+		}
+	}
+	packed, err := packUserOperation(op)
+	if err != nil {
+		return nil, err
+	}
+
+	callDataCost := uint64(0)
+	for _, b := range packed {
+		if b == 0 {
+			callDataCost += pvgZeroByte
+		} else {
+			callDataCost += pvgNonZeroByte
+		}
+	}
+	words := uint64(len(packed)+31) / 32
+	result := callDataCost + pvgFixed/pvgBundleSize + pvgPerUserOp + pvgPerUserOpWord*words
+	return big.NewInt(0).SetUint64(result), nil
+}
+
+// placeholderGas returns gas given to missing gas fields while estimating:
+// block gas limit, capped at the largest value accepted by EntryPoint.
+func placeholderGas(blockGasLimit uint64) *big.Int {
+	gas := big.NewInt(0).SetUint64(blockGasLimit)
+	if gas.Cmp(maxGasValue) > 0 {
+		return big.NewInt(0).Set(maxGasValue)
+	}
+	return gas
+}
+
+func isMissingGas(gas *big.Int) bool {
+	return gas == nil || gas.Sign() == 0
+}
+
+// NeedsPlaceholderGas reports whether `verificationGas` or `callGas` of op is missing.
+func NeedsPlaceholderGas(op abi.UserOperation) bool {
+	return isMissingGas(op.VerificationGas) || isMissingGas(op.CallGas)
+}
+
+// FillPlaceholderGas sets missing `verificationGas` and `callGas` of op to placeholderGas.
+// Wallets signing over gas fields should be signed after this, so that the signature matches while estimating.
+func FillPlaceholderGas(op *abi.UserOperation, blockGasLimit uint64) {
+	if isMissingGas(op.VerificationGas) {
+		op.VerificationGas = placeholderGas(blockGasLimit)
+	}
+	if isMissingGas(op.CallGas) {
+		op.CallGas = placeholderGas(blockGasLimit)
+	}
+}
+
+// EstimateUserOperationGas estimates gas fields of a partially filled user operation.
+// Missing `verificationGas` and `callGas` are simulated with FillPlaceholderGas,
+// since `simulateValidation` reverts if validation uses more than `verificationGas`.
+// The wallet must be able to prefund the placeholders at the given fees.
+func (e *EntryPoint) EstimateUserOperationGas(ctx context.Context, op abi.UserOperation) (GasEstimate, error) {
+	preVerificationGas, err := CalcPreVerificationGas(op)
+	if err != nil {
+		return GasEstimate{}, err
+	}
+
+	if NeedsPlaceholderGas(op) {
+		header, err := e.client.HeaderByNumber(ctx, nil)
+		if err != nil {
+			return GasEstimate{}, xerrors.Errorf("failed to get latest block header: %w", err)
+		}
+		FillPlaceholderGas(&op, header.GasLimit)
+	}
+
+	simulated, err := e.Simulate(ctx, op)
+	if err != nil {
+		return GasEstimate{}, xerrors.Errorf("failed to simulate validation: %w", err)
+	}
+	// `preOpGas` includes `preVerificationGas` given in op.
+	verificationGas := big.NewInt(0).Sub(simulated.PreOpGas, op.PreVerificationGas)
+
+	callGas := big.NewInt(0)
+	if len(op.CallData) > 0 {
+		entrypoint := e.Address
+		gas, err := e.client.EstimateGas(ctx, ethereum.CallMsg{
+			From: entrypoint,
+			To:   &op.Sender,
+			Data: op.CallData,
+		})
+		if err != nil {
+			return GasEstimate{}, xerrors.Errorf("failed to estimate call gas: %w", decodeRevertError(err))
+		}
+		callGas.SetUint64(gas)
+	}
+
+	return GasEstimate{
+		CallGas:            callGas,
+		VerificationGas:    verificationGas,
+		PreVerificationGas: preVerificationGas,
+	}, nil
+}
+
+// calcBundleGasLimit returns gas limit of `handleOps` transaction: estimated gas plus marginPercent.
+// Limits declared by operations are not summed, since they are upper bounds often far above actual usage.
+// The result is capped at blockGasLimit, and an error is returned if the estimate cannot fit in a block anyway.
+func calcBundleGasLimit(estimated uint64, blockGasLimit uint64, marginPercent uint64) (uint64, error) {
+	if estimated > blockGasLimit {
+		return 0, xerrors.Errorf("bundle needs %d gas, exceeding block gas limit %d", estimated, blockGasLimit)
+	}
+
+	limit := big.NewInt(0).SetUint64(estimated)
+	limit.Mul(limit, big.NewInt(0).SetUint64(100+marginPercent))
+	limit.Div(limit, big.NewInt(100))
+	if !limit.IsUint64() || limit.Uint64() > blockGasLimit {
+		return blockGasLimit, nil
+	}
+	return limit.Uint64(), nil
+}
+
+// EstimateBundleGas returns gas limit of `handleOps` transaction sent by bundler.
+// Reverts in `eth_estimateGas` are returned as *FailedOpError or *RevertError.
+func (e *EntryPoint) EstimateBundleGas(ctx context.Context, ops []abi.UserOperation, bundler common.Address) (uint64, error) {
+	entrypointABI, err := abi.EntryPointMetaData.GetAbi()
+	if err != nil {
+		return 0, err
+	}
+	data, err := entrypointABI.Pack("handleOps", ops, bundler)
+	if err != nil {
+		return 0, xerrors.Errorf("failed to pack handleOps call: %w", err)
+	}
+
+	entrypoint := e.Address
+	estimated, err := e.client.EstimateGas(ctx, ethereum.CallMsg{
+		From: bundler,
+		To:   &entrypoint,
+		Data: data,
+	})
+	if err != nil {
+		return 0, decodeRevertError(err)
+	}
+
+	header, err := e.client.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return 0, xerrors.Errorf("failed to get latest block header: %w", err)
+	}
+	return calcBundleGasLimit(estimated, header.GasLimit, e.Config.GetGasLimitMargin())
+}
@@ -0,0 +1,79 @@
+package eth
+
+import (
+	"bundler/abi"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+)
+
+func emptyOperation() abi.UserOperation {
+	return abi.UserOperation{
+		Sender:               common.HexToAddress("0x7f477B448FA08E8801c7fe44546e6aEae9Daae19"),
+		Nonce:                big.NewInt(0),
+		InitCode:             []byte{},
+		CallData:             []byte{},
+		CallGas:              big.NewInt(0),
+		VerificationGas:      big.NewInt(0),
+		PreVerificationGas:   big.NewInt(0),
+		MaxFeePerGas:         big.NewInt(0),
+		MaxPriorityFeePerGas: big.NewInt(0),
+		Paymaster:            common.Address{},
+		PaymasterData:        []byte{},
+		Signature:            []byte{},
+	}
+}
+
+func Test_CalcPreVerificationGas(t *testing.T) {
+	t.Run("calldata cost", func(t *testing.T) {
+		zeros := emptyOperation()
+		zeros.CallData = make([]byte, 32)
+		zerosGas, err := CalcPreVerificationGas(zeros)
+		require.NoError(t, err)
+
+		nonZeros := emptyOperation()
+		nonZeros.CallData = common.Hex2Bytes("ffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff")
+		nonZerosGas, err := CalcPreVerificationGas(nonZeros)
+		require.NoError(t, err)
+
+		require.EqualValues(t, 32*(pvgNonZeroByte-pvgZeroByte), nonZerosGas.Int64()-zerosGas.Int64())
+	})
+
+	t.Run("dummy signature", func(t *testing.T) {
+		unsigned := emptyOperation()
+		unsignedGas, err := CalcPreVerificationGas(unsigned)
+		require.NoError(t, err)
+
+		signed := emptyOperation()
+		signed.Signature = make([]byte, pvgSigSize)
+		for i := range signed.Signature {
+			signed.Signature[i] = 0xff
+		}
+		signedGas, err := CalcPreVerificationGas(signed)
+		require.NoError(t, err)
+
+		require.Equal(t, signedGas, unsignedGas)
+		require.Greater(t, unsignedGas.Int64(), int64(pvgFixed+pvgPerUserOp))
+	})
+}
+
+func Test_calcBundleGasLimit(t *testing.T) {
+	t.Run("estimate plus margin", func(t *testing.T) {
+		limit, err := calcBundleGasLimit(1000000, 30000000, 20)
+		require.NoError(t, err)
+		require.EqualValues(t, 1200000, limit)
+	})
+
+	t.Run("capped at block gas limit", func(t *testing.T) {
+		limit, err := calcBundleGasLimit(950000, 1000000, 10)
+		require.NoError(t, err)
+		require.EqualValues(t, 1000000, limit)
+	})
+
+	t.Run("exceeding block gas limit", func(t *testing.T) {
+		_, err := calcBundleGasLimit(1000001, 1000000, 10)
+		require.Error(t, err)
+	})
+}
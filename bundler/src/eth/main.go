@@ -4,92 +4,246 @@ import (
 	"context"
 	"fmt"
 	"math/big"
+	"sync"
 
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
-	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
 	"github.com/sirupsen/logrus"
 	"golang.org/x/xerrors"
 
 	"bundler/abi"
 	"bundler/config"
+	"bundler/signer"
 )
 
 var (
-	client *ethclient.Client
+	// Every configured chain, in config order.
+	chains []*Chain
 	l      = logrus.WithFields(logrus.Fields{
 		"module": "eth",
 	})
 )
 
+// Chain is the connection to one configured chain.
+type Chain struct {
+	ID     *big.Int
+	Config *config.ChainConfig
+
+	client *ethclient.Client
+	// Raw RPC client for methods not wrapped by ethclient, e.g. `debug_traceCall`.
+	rpcClient *rpc.Client
+
+	// Every supported entry point, the first one is the default.
+	EntryPoints []*EntryPoint
+
+	nonceManagersMu sync.Mutex
+	nonceManagers   map[common.Address]*NonceManager
+}
+
+// EntryPoint is one EntryPoint contract on a chain.
+// Bundler keys and their nonces are shared by all entry points of the chain.
+type EntryPoint struct {
+	*Chain
+	Address common.Address
+}
+
 type SimulateResult struct {
+	// Total gas used by validation, including `preVerificationGas`.
 	PreOpGas *big.Int
-	Prefund  *big.Int
+	// Amount the wallet had to prefund. Zero if paymaster pays.
+	Prefund *big.Int
+}
+
+// NewChain connects to RPC server of a configured chain.
+func NewChain(chainConfig *config.ChainConfig) (*Chain, error) {
+	rpcClient, err := rpc.Dial(chainConfig.RPCServer)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to connect to RPC server of chain %s: %w", chainConfig.ChainID, err)
+	}
+	chain := &Chain{
+		ID:            chainConfig.GetChainID(),
+		Config:        chainConfig,
+		client:        ethclient.NewClient(rpcClient),
+		rpcClient:     rpcClient,
+		nonceManagers: make(map[common.Address]*NonceManager),
+	}
+	for _, address := range chainConfig.GetEntrypointContractAddresses() {
+		chain.EntryPoints = append(chain.EntryPoints, &EntryPoint{Chain: chain, Address: address})
+	}
+	return chain, nil
 }
 
 func Init() {
-	if client != nil {
+	if chains != nil {
 		return
 	}
 
-	var err error
-	client, err = ethclient.Dial(config.C.Chain.RPCServer)
-	if err != nil {
-		panic(fmt.Sprintf("Failed to connect to the Ethereum client: %s", err.Error()))
+	for _, chainConfig := range config.GetChains() {
+		chain, err := NewChain(chainConfig)
+		if err != nil {
+			panic(fmt.Sprintf("Failed to connect to the Ethereum client: %s", err.Error()))
+		}
+		chains = append(chains, chain)
 	}
 }
 
-func Simulate(ctx context.Context, op abi.UserOperation) error {
+// Chains returns every configured chain. The first one is served by default.
+func Chains() []*Chain {
+	return chains
+}
+
+// GetChain returns the chain with given ID, nil if it is not configured.
+func GetChain(chainID *big.Int) *Chain {
+	for _, chain := range chains {
+		if chain.ID.Cmp(chainID) == 0 {
+			return chain
+		}
+	}
+	return nil
+}
+
+// EntryPoint returns the entry point at address, nil if it is not supported.
+func (c *Chain) EntryPoint(address common.Address) *EntryPoint {
+	for _, entrypoint := range c.EntryPoints {
+		if entrypoint.Address == address {
+			return entrypoint
+		}
+	}
+	return nil
+}
+
+// DefaultEntryPoint returns the entry point used when a request does not name one.
+func (c *Chain) DefaultEntryPoint() *EntryPoint {
+	return c.EntryPoints[0]
+}
+
+// Simulate runs `simulateValidation` as `eth_call` from zero address.
+// Reverts are returned as *FailedOpError or *RevertError.
+// If `trace_validation` is enabled, banned opcodes are returned as *BannedOpcodeError.
+func (e *EntryPoint) Simulate(ctx context.Context, op abi.UserOperation) (SimulateResult, error) {
 	// Init contract
-	entrypoint, err := abi.NewEntryPoint(config.GetEntrypointContractAddress(), client)
+	entrypoint, err := abi.NewEntryPoint(e.Address, e.client)
+	if err != nil {
+		return SimulateResult{}, err
+	}
+
+	// Start simulation
+	out := []interface{}{}
+	raw := abi.EntryPointRaw{Contract: entrypoint}
+	// Zero address `from` required by contract
+	err = raw.Call(&bind.CallOpts{Context: ctx, From: common.Address{}}, &out, "simulateValidation", op)
 	if err != nil {
-		return err
+		err = decodeRevertError(err)
+		l.Warnf("Simulation failed. Error: %s", err.Error())
+		return SimulateResult{}, err
 	}
 
-	signer := types.LatestSignerForChainID(config.GetChainID())
-	tOps := &bind.TransactOpts{
-		// Zero address `from` required by contract
-		From: common.Address{},
-		// Basiclly bind.NewKeyedTransactorWithChainID() but omit `ErrNotAuthorized` check
+	if e.Config.TraceValidation {
+		if err := e.TraceValidation(ctx, op); err != nil {
+			l.Warnf("Validation trace rejected operation. Error: %s", err.Error())
+			return SimulateResult{}, err
+		}
+	}
+
+	return SimulateResult{
+		PreOpGas: out[0].(*big.Int),
+		Prefund:  out[1].(*big.Int),
+	}, nil
+}
+
+// BaseFee returns base fee of latest block, nil if the chain is not EIP-1559 enabled.
+func (c *Chain) BaseFee(ctx context.Context) (*big.Int, error) {
+	header, err := c.client.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to get latest block header: %w", err)
+	}
+	return header.BaseFee, nil
+}
+
+// SendHandleOps sends ops in a `handleOps` transaction, signed by the bundler with fewest transactions in flight.
+// Reverts in gas estimation are returned as *FailedOpError or *RevertError.
+// If the nonce turns out to be used, nonce is resynced and the transaction is sent once more.
+func (e *EntryPoint) SendHandleOps(ctx context.Context, ops []abi.UserOperation) (*types.Transaction, error) {
+	// Gas used by `handleOps` does not depend on sender.
+	gasLimit, err := e.EstimateBundleGas(ctx, ops, e.Config.GetBundlerAddress())
+	if err != nil {
+		return nil, err
+	}
+	bundler, err := e.SelectBundler(ctx, gasLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := e.sendHandleOps(ctx, bundler, gasLimit, ops)
+	if err != nil && isNonceError(err) {
+		address := bundler.Address()
+		l.Warnf("Nonce of bundler %s is used, resyncing. Error: %s", address.Hex(), err.Error())
+		if err := e.ResyncNonce(ctx, address); err != nil {
+			return nil, err
+		}
+		tx, err = e.sendHandleOps(ctx, bundler, gasLimit, ops)
+	}
+	return tx, err
+}
+
+func (e *EntryPoint) sendHandleOps(ctx context.Context, bundler signer.Signer, gasLimit uint64, ops []abi.UserOperation) (*types.Transaction, error) {
+	// Init contract
+	entrypoint, err := abi.NewEntryPoint(e.Address, e.client)
+	if err != nil {
+		return nil, err
+	}
+	chainID := e.ID
+	transactOps := &bind.TransactOpts{
+		From: bundler.Address(),
 		Signer: func(address common.Address, tx *types.Transaction) (*types.Transaction, error) {
-			signature, err := crypto.Sign(signer.Hash(tx).Bytes(), config.GetBundler())
-			if err != nil {
-				return nil, err
+			if address != bundler.Address() {
+				return nil, bind.ErrNotAuthorized
 			}
-			return tx.WithSignature(signer, signature)
+			return bundler.SignTx(ctx, tx, chainID)
 		},
-		Context: ctx,
-		NoSend:  true,
+		Context:  ctx,
+		GasLimit: gasLimit,
 	}
 
-	// Start simulation
-	_, err = entrypoint.SimulateValidation(tOps, op)
+	nonces := e.nonceManagerOf(transactOps.From)
+	nonce, err := nonces.Acquire(ctx)
 	if err != nil {
-		l.Warnf("Simulation failed. Error: %s", err.Error())
-		return err
+		return nil, err
 	}
-	return nil
+	transactOps.Nonce = big.NewInt(0).SetUint64(nonce)
+
+	// Fees are paid back to the sender.
+	tx, err := entrypoint.HandleOps(transactOps, ops, transactOps.From)
+	if err != nil {
+		nonces.Release(nonce)
+		return nil, decodeRevertError(err)
+	}
+	nonces.Sent(nonce)
+	return tx, nil
 }
 
-func HandleOps(ctx context.Context, ops []abi.UserOperation) (txHash string, err error) {
-	// Init contract
-	entrypoint, err := abi.NewEntryPoint(config.GetEntrypointContractAddress(), client)
+func (e *EntryPoint) HandleOps(ctx context.Context, ops []abi.UserOperation) (txHash string, err error) {
+	tx, err := e.SendHandleOps(ctx, ops)
 	if err != nil {
 		return "", err
 	}
-	transactOps, err := bind.NewKeyedTransactorWithChainID(config.GetBundler(), config.GetChainID())
+	return tx.Hash().Hex(), nil
+}
+
+// GetRequestID asks EntryPoint contract for request ID of given user operation.
+// RequestID computes the same value without a call.
+func (e *EntryPoint) GetRequestID(ctx context.Context, op abi.UserOperation) (common.Hash, error) {
+	entrypoint, err := abi.NewEntryPoint(e.Address, e.client)
 	if err != nil {
-		return "", xerrors.Errorf("Failed to create transactor for bundler: %w", err)
+		return common.Hash{}, err
 	}
-	transactOps.Context = ctx
-	transactOps.GasLimit *= 2 // FIXME: sometimes estimated gas is wrong.
 
-	tx, err := entrypoint.HandleOps(transactOps, ops, config.GetBundlerAddress())
+	requestID, err := entrypoint.GetRequestId(&bind.CallOpts{Context: ctx}, op)
 	if err != nil {
-		return "", err
+		return common.Hash{}, xerrors.Errorf("failed to get request ID: %w", err)
 	}
-
-	return tx.Hash().Hex(), nil
+	return requestID, nil
 }
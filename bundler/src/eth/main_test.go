@@ -26,6 +26,7 @@ var (
 	USER_ADDRESS common.Address
 
 	erc20Contract *abi.ERC20
+	testChain     *Chain
 )
 
 func getContractWalletAddress() common.Address {
@@ -41,12 +42,12 @@ func getPaymasterAddress() common.Address {
 }
 
 func prepareTransactOpts(t *testing.T, ctx context.Context, from common.Address) *bind.TransactOpts {
-	nonce, err := client.NonceAt(ctx, from, nil)
+	nonce, err := testChain.client.NonceAt(ctx, from, nil)
 	if err != nil {
 		t.Fatalf("failed to get nonce for 0x%s: %s", from.Hex(), err.Error())
 	}
 
-	gasTipCap, err := client.SuggestGasTipCap(ctx)
+	gasTipCap, err := testChain.client.SuggestGasTipCap(ctx)
 	if err != nil {
 		t.Fatalf("failed to get gas price: %s", err.Error())
 	}
@@ -68,6 +69,7 @@ func prepareTransactOpts(t *testing.T, ctx context.Context, from common.Address)
 func before_each(t *testing.T) {
 	config.InitFromFile("../config/config.test.json")
 	Init()
+	testChain = Chains()[0]
 
 	if config.C.Test == nil {
 		t.Fatalf("Test config is not defined")
@@ -80,7 +82,7 @@ func before_each(t *testing.T) {
 			t.Fatalf("%s", err.Error())
 		}
 		USER_PUBLIC = USER.PublicKey
-		USER_BIND, err = bind.NewKeyedTransactorWithChainID(USER, config.GetChainID())
+		USER_BIND, err = bind.NewKeyedTransactorWithChainID(USER, testChain.ID)
 		if err != nil {
 			t.Fatalf("%s", err.Error())
 		}
@@ -90,7 +92,7 @@ func before_each(t *testing.T) {
 
 	if erc20Contract == nil {
 		var err error
-		erc20Contract, err = abi.NewERC20(getERC20Address(), client)
+		erc20Contract, err = abi.NewERC20(getERC20Address(), testChain.client)
 		if err != nil {
 			t.Fatalf("%s", err.Error())
 		}
@@ -164,8 +166,23 @@ func Test_Simulate(t *testing.T) {
 
 		// User transfer 100 tokens from user contract wallet to itself.
 		uo := SimulateOperation()
-		err := Simulate(context.Background(), uo)
+		result, err := testChain.DefaultEntryPoint().Simulate(context.Background(), uo)
 		require.NoError(t, err)
+		t.Logf("preOpGas: %s, prefund: %s", result.PreOpGas.String(), result.Prefund.String())
+	})
+}
+
+func Test_GetRequestID(t *testing.T) {
+	t.Run("same as off-chain request ID", func(t *testing.T) {
+		before_each(t)
+
+		uo := SimulateOperation()
+		entrypoint := testChain.DefaultEntryPoint()
+		expected, err := entrypoint.GetRequestID(context.Background(), uo)
+		require.NoError(t, err)
+		actual, err := entrypoint.RequestID(uo)
+		require.NoError(t, err)
+		require.Equal(t, expected, actual)
 	})
 }
 
@@ -176,7 +193,7 @@ func Test_HandleOps(t *testing.T) {
 		before_each(t)
 
 		uo := SimulateOperation()
-		txHash, err := HandleOps(context.Background(), []abi.UserOperation{uo})
+		txHash, err := testChain.DefaultEntryPoint().HandleOps(context.Background(), []abi.UserOperation{uo})
 		require.NoError(t, err)
 		t.Logf("Tx Hash: %s", txHash)
 	})
@@ -0,0 +1,142 @@
+package eth
+
+import (
+	"context"
+	"strings"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"golang.org/x/xerrors"
+)
+
+// PendingNonceFunc returns next nonce of account including pending transactions, e.g. `ethclient.Client.PendingNonceAt`.
+type PendingNonceFunc func(ctx context.Context, account common.Address) (uint64, error)
+
+// NonceManager hands out nonces of one account to concurrent senders.
+//
+// Every acquired nonce must be either marked as sent, or released if its transaction is not sent.
+// Sent nonces stay reserved until pending nonce of the node moves past them, or until Resync.
+// Nonces between pending nonce of the node and the next local nonce which are neither in flight nor sent
+// were abandoned, so they are handed out again first.
+type NonceManager struct {
+	mu             sync.Mutex
+	account        common.Address
+	pendingNonceAt PendingNonceFunc
+	next           uint64
+	inflight       map[uint64]bool
+	sent           map[uint64]bool
+}
+
+func NewNonceManager(account common.Address, pendingNonceAt PendingNonceFunc) *NonceManager {
+	return &NonceManager{
+		account:        account,
+		pendingNonceAt: pendingNonceAt,
+		inflight:       make(map[uint64]bool),
+		sent:           make(map[uint64]bool),
+	}
+}
+
+// Acquire returns the lowest nonce not used by sent or in-flight transactions.
+func (m *NonceManager) Acquire(ctx context.Context) (uint64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	pending, err := m.pendingNonceAt(ctx, m.account)
+	if err != nil {
+		return 0, xerrors.Errorf("failed to get pending nonce of %s: %w", m.account.Hex(), err)
+	}
+	// Someone else sent transactions with the same key.
+	if pending > m.next {
+		m.next = pending
+	}
+	for n := range m.sent {
+		if n < pending {
+			delete(m.sent, n)
+		}
+	}
+
+	nonce := m.next
+	for n := pending; n < m.next; n++ {
+		if !m.inflight[n] && !m.sent[n] {
+			l.Warnf("Filling nonce gap %d of %s", n, m.account.Hex())
+			nonce = n
+			break
+		}
+	}
+	if nonce == m.next {
+		m.next++
+	}
+	m.inflight[nonce] = true
+	return nonce, nil
+}
+
+// Sent marks nonce as used by a transaction accepted by the node.
+// It is not handed out again while the node has not counted it in pending nonce yet.
+func (m *NonceManager) Sent(nonce uint64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.inflight, nonce)
+	m.sent[nonce] = true
+}
+
+// Release marks nonce as no longer in flight, since its transaction is not going to be sent.
+func (m *NonceManager) Release(nonce uint64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.inflight, nonce)
+}
+
+// InFlight returns how many nonces are acquired but neither sent nor released.
+func (m *NonceManager) InFlight() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.inflight)
+}
+
+// Resync resets next nonce to pending nonce of the node, e.g. after a transaction is dropped.
+// Sent nonces not counted by the node are forgotten, so that they are handed out again.
+func (m *NonceManager) Resync(ctx context.Context) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	pending, err := m.pendingNonceAt(ctx, m.account)
+	if err != nil {
+		return xerrors.Errorf("failed to get pending nonce of %s: %w", m.account.Hex(), err)
+	}
+	m.sent = make(map[uint64]bool)
+	// Nonces in flight must not be handed out again.
+	for n := range m.inflight {
+		if n >= pending {
+			pending = n + 1
+		}
+	}
+	l.Infof("Nonce of %s resynced from %d to %d", m.account.Hex(), m.next, pending)
+	m.next = pending
+	return nil
+}
+
+// isNonceError tells if a transaction is rejected because its nonce is already used.
+func isNonceError(err error) bool {
+	message := strings.ToLower(err.Error())
+	return strings.Contains(message, "nonce too low") ||
+		strings.Contains(message, "replacement transaction underpriced") ||
+		strings.Contains(message, "already known")
+}
+
+// nonceManagerOf returns the shared NonceManager of a bundler account on the chain.
+func (c *Chain) nonceManagerOf(account common.Address) *NonceManager {
+	c.nonceManagersMu.Lock()
+	defer c.nonceManagersMu.Unlock()
+
+	manager, ok := c.nonceManagers[account]
+	if !ok {
+		manager = NewNonceManager(account, c.client.PendingNonceAt)
+		c.nonceManagers[account] = manager
+	}
+	return manager
+}
+
+// ResyncNonce resyncs nonce of a bundler account with the node.
+func (c *Chain) ResyncNonce(ctx context.Context, account common.Address) error {
+	return c.nonceManagerOf(account).Resync(ctx)
+}
@@ -0,0 +1,173 @@
+package eth
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/xerrors"
+)
+
+// fakeNode counts nonces of transactions accepted into its pool.
+type fakeNode struct {
+	mu      sync.Mutex
+	pending uint64
+}
+
+func (n *fakeNode) pendingNonceAt(ctx context.Context, account common.Address) (uint64, error) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return n.pending, nil
+}
+
+func (n *fakeNode) send(nonce uint64) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if nonce == n.pending {
+		n.pending++
+	}
+}
+
+func Test_NonceManager(t *testing.T) {
+	ctx := context.Background()
+	account := common.HexToAddress("0x0a")
+
+	t.Run("concurrent", func(t *testing.T) {
+		node := &fakeNode{pending: 3}
+		manager := NewNonceManager(account, node.pendingNonceAt)
+
+		mu := sync.Mutex{}
+		seen := make(map[uint64]bool)
+		wg := sync.WaitGroup{}
+		for i := 0; i < 50; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				nonce, err := manager.Acquire(ctx)
+				require.NoError(t, err)
+				mu.Lock()
+				require.False(t, seen[nonce], "nonce %d handed out twice", nonce)
+				seen[nonce] = true
+				mu.Unlock()
+			}()
+		}
+		wg.Wait()
+		for nonce := uint64(3); nonce < 53; nonce++ {
+			require.True(t, seen[nonce])
+		}
+	})
+
+	t.Run("released nonce is reused", func(t *testing.T) {
+		node := &fakeNode{}
+		manager := NewNonceManager(account, node.pendingNonceAt)
+
+		first, err := manager.Acquire(ctx)
+		require.NoError(t, err)
+		second, err := manager.Acquire(ctx)
+		require.NoError(t, err)
+		require.EqualValues(t, 0, first)
+		require.EqualValues(t, 1, second)
+
+		// First is not sent
+		manager.Release(first)
+		nonce, err := manager.Acquire(ctx)
+		require.NoError(t, err)
+		require.EqualValues(t, 0, nonce)
+	})
+
+	t.Run("sent nonce is not reused before node counts it", func(t *testing.T) {
+		node := &fakeNode{}
+		manager := NewNonceManager(account, node.pendingNonceAt)
+
+		first, err := manager.Acquire(ctx)
+		require.NoError(t, err)
+		second, err := manager.Acquire(ctx)
+		require.NoError(t, err)
+		// First is sent, but pending nonce of the node lags behind.
+		manager.Sent(first)
+		require.Zero(t, node.pending)
+
+		nonce, err := manager.Acquire(ctx)
+		require.NoError(t, err)
+		require.EqualValues(t, 2, nonce)
+		require.Equal(t, 2, manager.InFlight())
+
+		// Second is sent and counted, so that first is no longer reserved.
+		node.send(first)
+		node.send(second)
+		manager.Sent(second)
+		manager.Release(nonce)
+		nonce, err = manager.Acquire(ctx)
+		require.NoError(t, err)
+		require.EqualValues(t, 2, nonce)
+		require.Empty(t, manager.sent)
+	})
+
+	t.Run("dropped transaction is refilled after resync", func(t *testing.T) {
+		node := &fakeNode{}
+		manager := NewNonceManager(account, node.pendingNonceAt)
+
+		for i := 0; i < 3; i++ {
+			nonce, err := manager.Acquire(ctx)
+			require.NoError(t, err)
+			manager.Sent(nonce)
+		}
+		// Node drops nonce 1 and 2, which stay reserved until resync.
+		node.send(0)
+
+		nonce, err := manager.Acquire(ctx)
+		require.NoError(t, err)
+		require.EqualValues(t, 3, nonce)
+		manager.Release(nonce)
+
+		require.NoError(t, manager.Resync(ctx))
+		nonce, err = manager.Acquire(ctx)
+		require.NoError(t, err)
+		require.EqualValues(t, 1, nonce)
+		node.send(nonce)
+		manager.Sent(nonce)
+
+		nonce, err = manager.Acquire(ctx)
+		require.NoError(t, err)
+		require.EqualValues(t, 2, nonce)
+	})
+
+	t.Run("nonces used by another process", func(t *testing.T) {
+		node := &fakeNode{}
+		manager := NewNonceManager(account, node.pendingNonceAt)
+
+		nonce, err := manager.Acquire(ctx)
+		require.NoError(t, err)
+		require.EqualValues(t, 0, nonce)
+		node.pending = 5
+
+		nonce, err = manager.Acquire(ctx)
+		require.NoError(t, err)
+		require.EqualValues(t, 5, nonce)
+	})
+
+	t.Run("resync keeps nonces in flight", func(t *testing.T) {
+		node := &fakeNode{}
+		manager := NewNonceManager(account, node.pendingNonceAt)
+
+		for i := 0; i < 3; i++ {
+			_, err := manager.Acquire(ctx)
+			require.NoError(t, err)
+		}
+		manager.Release(2)
+		require.NoError(t, manager.Resync(ctx))
+		require.EqualValues(t, 2, manager.next)
+
+		nonce, err := manager.Acquire(ctx)
+		require.NoError(t, err)
+		require.EqualValues(t, 2, nonce)
+	})
+
+	t.Run("nonce error", func(t *testing.T) {
+		require.True(t, isNonceError(xerrors.Errorf("wrapped: %w", xerrors.New("nonce too low"))))
+		require.True(t, isNonceError(xerrors.New("replacement transaction underpriced")))
+		require.False(t, isNonceError(xerrors.New("execution reverted")))
+	})
+}
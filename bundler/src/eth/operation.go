@@ -0,0 +1,90 @@
+package eth
+
+import (
+	"bytes"
+	"context"
+	"math/big"
+
+	gethabi "github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"golang.org/x/xerrors"
+
+	"bundler/abi"
+)
+
+type IncludedUserOperation struct {
+	UserOperation   abi.UserOperation
+	EntryPoint      common.Address
+	BlockNumber     uint64
+	BlockHash       common.Hash
+	TransactionHash common.Hash
+}
+
+// decodeHandleOps decodes user operations from `handleOps` calldata.
+func decodeHandleOps(data []byte) ([]abi.UserOperation, error) {
+	entrypointABI, err := abi.EntryPointMetaData.GetAbi()
+	if err != nil {
+		return nil, err
+	}
+	method := entrypointABI.Methods["handleOps"]
+	if len(data) < 4 || !bytes.Equal(data[:4], method.ID) {
+		return nil, xerrors.Errorf("calldata is not a handleOps call")
+	}
+
+	args, err := method.Inputs.Unpack(data[4:])
+	if err != nil {
+		return nil, xerrors.Errorf("failed to unpack handleOps calldata: %w", err)
+	}
+	ops := *gethabi.ConvertType(args[0], new([]abi.UserOperation)).(*[]abi.UserOperation)
+	return ops, nil
+}
+
+// findOperation returns the operation in ops whose request ID on entryPoint and chainID is requestID.
+// Request ID covers every field but signature, so operations sharing sender and nonce are told apart.
+func findOperation(ops []abi.UserOperation, requestID common.Hash, entryPoint common.Address, chainID *big.Int) (*abi.UserOperation, error) {
+	for i := range ops {
+		id, err := RequestID(ops[i], entryPoint, chainID)
+		if err != nil {
+			return nil, err
+		}
+		if id == requestID {
+			return &ops[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// GetUserOperationByRequestID reconstructs an included user operation from
+// calldata of the transaction emitting its `UserOperationEvent`.
+// Returns nil if the operation is not included in recent blocks.
+func (c *Chain) GetUserOperationByRequestID(ctx context.Context, requestID common.Hash) (*IncludedUserOperation, error) {
+	_, event, err := c.searchUserOperationEvent(ctx, requestID)
+	if err != nil || event == nil {
+		return nil, err
+	}
+
+	tx, _, err := c.client.TransactionByHash(ctx, event.Raw.TxHash)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to get transaction %s: %w", event.Raw.TxHash.Hex(), err)
+	}
+	ops, err := decodeHandleOps(tx.Data())
+	if err != nil {
+		// e.g. handleOps is called by another contract.
+		return nil, xerrors.Errorf("failed to decode transaction %s: %w", event.Raw.TxHash.Hex(), err)
+	}
+
+	op, err := findOperation(ops, requestID, event.Raw.Address, c.ID)
+	if err != nil {
+		return nil, err
+	}
+	if op == nil {
+		return nil, xerrors.Errorf("operation not found in transaction %s", event.Raw.TxHash.Hex())
+	}
+	return &IncludedUserOperation{
+		UserOperation:   *op,
+		EntryPoint:      event.Raw.Address,
+		BlockNumber:     event.Raw.BlockNumber,
+		BlockHash:       event.Raw.BlockHash,
+		TransactionHash: event.Raw.TxHash,
+	}, nil
+}
@@ -0,0 +1,73 @@
+package eth
+
+import (
+	"bundler/abi"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_decodeHandleOps(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		entrypointABI, err := abi.EntryPointMetaData.GetAbi()
+		require.NoError(t, err)
+
+		op := emptyOperation()
+		op.Nonce = big.NewInt(4)
+		op.CallData = common.FromHex("0x80c5c7d0")
+		op.Signature = common.FromHex("0xe0dd")
+		data, err := entrypointABI.Pack("handleOps", []abi.UserOperation{op, emptyOperation()}, common.Address{})
+		require.NoError(t, err)
+
+		ops, err := decodeHandleOps(data)
+		require.NoError(t, err)
+		require.Len(t, ops, 2)
+		require.Equal(t, op.Sender, ops[0].Sender)
+		expected, err := packUserOperation(op)
+		require.NoError(t, err)
+		actual, err := packUserOperation(ops[0])
+		require.NoError(t, err)
+		require.Equal(t, expected, actual)
+	})
+
+	t.Run("other method", func(t *testing.T) {
+		_, err := decodeHandleOps(common.FromHex("0xdeadbeef"))
+		require.Error(t, err)
+	})
+}
+
+func Test_findOperation(t *testing.T) {
+	entryPoint := common.HexToAddress("0x8A42F70047a99298822dD1dbA34b454fc49913F2")
+	chainID := big.NewInt(80001)
+
+	first := emptyOperation()
+	second := emptyOperation()
+	second.Nonce = big.NewInt(1)
+	// Same sender and nonce as second, e.g. a replaced operation.
+	replaced := emptyOperation()
+	replaced.Nonce = big.NewInt(1)
+	replaced.CallGas = big.NewInt(50000)
+	ops := []abi.UserOperation{first, replaced, second}
+
+	t.Run("same sender", func(t *testing.T) {
+		for _, expected := range ops {
+			requestID, err := RequestID(expected, entryPoint, chainID)
+			require.NoError(t, err)
+			op, err := findOperation(ops, requestID, entryPoint, chainID)
+			require.NoError(t, err)
+			require.NotNil(t, op)
+			require.Equal(t, expected.Nonce, op.Nonce)
+			require.Equal(t, expected.CallGas, op.CallGas)
+		}
+	})
+
+	t.Run("other chain", func(t *testing.T) {
+		requestID, err := RequestID(second, entryPoint, chainID)
+		require.NoError(t, err)
+		op, err := findOperation(ops, requestID, entryPoint, big.NewInt(137))
+		require.NoError(t, err)
+		require.Nil(t, op)
+	})
+}
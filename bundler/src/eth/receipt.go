@@ -0,0 +1,128 @@
+package eth
+
+import (
+	"context"
+	"math/big"
+
+	gethabi "github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"golang.org/x/xerrors"
+
+	"bundler/abi"
+)
+
+type UserOperationReceipt struct {
+	RequestID      common.Hash
+	Sender         common.Address
+	Paymaster      common.Address
+	Nonce          *big.Int
+	ActualGasCost  *big.Int
+	ActualGasPrice *big.Int
+	Success        bool
+	// Decoded revert reason of `callData` execution. Empty if not reverted.
+	Reason  string
+	Receipt *types.Receipt
+}
+
+// findUserOperationEvent searches latest `GetLogLookbackBlocks()` blocks
+// for `UserOperationEvent` with given request ID.
+// Returns nil if not found.
+func (c *Chain) findUserOperationEvent(ctx context.Context, entrypoint *abi.EntryPoint, requestID common.Hash) (*abi.EntryPointUserOperationEvent, error) {
+	latest, err := c.client.BlockNumber(ctx)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to get latest block number: %w", err)
+	}
+	lookback := c.Config.GetLogLookbackBlocks()
+	start := uint64(0)
+	if latest > lookback {
+		start = latest - lookback
+	}
+
+	iter, err := entrypoint.FilterUserOperationEvent(&bind.FilterOpts{Start: start, Context: ctx}, [][32]byte{requestID}, nil, nil)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to filter UserOperationEvent: %w", err)
+	}
+	defer iter.Close()
+
+	var event *abi.EntryPointUserOperationEvent
+	for iter.Next() {
+		if iter.Event.Raw.Removed {
+			continue
+		}
+		event = iter.Event
+	}
+	if iter.Error() != nil {
+		return nil, xerrors.Errorf("failed to iterate UserOperationEvent: %w", iter.Error())
+	}
+	return event, nil
+}
+
+// searchUserOperationEvent searches every entry point of the chain for `UserOperationEvent` with given request ID.
+// Returns nil if not found.
+func (c *Chain) searchUserOperationEvent(ctx context.Context, requestID common.Hash) (*abi.EntryPoint, *abi.EntryPointUserOperationEvent, error) {
+	for _, ep := range c.EntryPoints {
+		entrypoint, err := abi.NewEntryPoint(ep.Address, c.client)
+		if err != nil {
+			return nil, nil, err
+		}
+		event, err := c.findUserOperationEvent(ctx, entrypoint, requestID)
+		if err != nil {
+			return nil, nil, err
+		}
+		if event != nil {
+			return entrypoint, event, nil
+		}
+	}
+	return nil, nil, nil
+}
+
+// decodeRevertReason decodes `Error(string)` revert data.
+// Other data is returned as hex string.
+func decodeRevertReason(data []byte) string {
+	if reason, err := gethabi.UnpackRevert(data); err == nil {
+		return reason
+	}
+	return hexutil.Encode(data)
+}
+
+// GetUserOperationReceipt finds execution result of a user operation by its request ID.
+// Returns nil if the operation is not included in recent blocks.
+func (c *Chain) GetUserOperationReceipt(ctx context.Context, requestID common.Hash) (*UserOperationReceipt, error) {
+	entrypoint, event, err := c.searchUserOperationEvent(ctx, requestID)
+	if err != nil || event == nil {
+		return nil, err
+	}
+
+	receipt, err := c.client.TransactionReceipt(ctx, event.Raw.TxHash)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to get transaction receipt: %w", err)
+	}
+
+	result := &UserOperationReceipt{
+		RequestID:      requestID,
+		Sender:         event.Sender,
+		Paymaster:      event.Paymaster,
+		Nonce:          event.Nonce,
+		ActualGasCost:  event.ActualGasCost,
+		ActualGasPrice: event.ActualGasPrice,
+		Success:        event.Success,
+		Receipt:        receipt,
+	}
+
+	// `UserOperationRevertReason` is emitted in the same transaction, before `UserOperationEvent`.
+	for _, log := range receipt.Logs {
+		if log.Address != event.Raw.Address || log.Index >= event.Raw.Index {
+			continue
+		}
+		revert, err := entrypoint.ParseUserOperationRevertReason(*log)
+		if err != nil || common.Hash(revert.RequestId) != requestID {
+			continue
+		}
+		result.Reason = decodeRevertReason(revert.RevertReason)
+	}
+
+	return result, nil
+}
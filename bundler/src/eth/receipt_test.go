@@ -0,0 +1,231 @@
+package eth
+
+import (
+	"context"
+	"math/big"
+	"net/http/httptest"
+	"testing"
+
+	gethabi "github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/stretchr/testify/require"
+
+	"bundler/abi"
+	"bundler/config"
+)
+
+// fakeLogNode answers `eth_getLogs` from logs, filtered by block range, address and topics.
+type fakeLogNode struct {
+	head uint64
+	logs []types.Log
+	// `fromBlock` of every `eth_getLogs` call.
+	fromBlocks []uint64
+}
+
+func (n *fakeLogNode) BlockNumber() hexutil.Uint64 {
+	return hexutil.Uint64(n.head)
+}
+
+func (n *fakeLogNode) GetLogs(filter struct {
+	FromBlock hexutil.Uint64   `json:"fromBlock"`
+	Address   []common.Address `json:"address"`
+	Topics    [][]common.Hash  `json:"topics"`
+}) []types.Log {
+	n.fromBlocks = append(n.fromBlocks, uint64(filter.FromBlock))
+	result := []types.Log{}
+	for _, log := range n.logs {
+		if log.BlockNumber < uint64(filter.FromBlock) || !containsAddress(filter.Address, log.Address) || !matchTopics(filter.Topics, log.Topics) {
+			continue
+		}
+		result = append(result, log)
+	}
+	return result
+}
+
+// GetTransactionReceipt returns every log of the transaction.
+func (n *fakeLogNode) GetTransactionReceipt(hash common.Hash) *types.Receipt {
+	receipt := &types.Receipt{Status: types.ReceiptStatusSuccessful, TxHash: hash, Logs: []*types.Log{}}
+	for i := range n.logs {
+		if n.logs[i].TxHash == hash {
+			receipt.Logs = append(receipt.Logs, &n.logs[i])
+			receipt.BlockNumber = big.NewInt(int64(n.logs[i].BlockNumber))
+		}
+	}
+	return receipt
+}
+
+func containsAddress(addresses []common.Address, address common.Address) bool {
+	for _, a := range addresses {
+		if a == address {
+			return true
+		}
+	}
+	return len(addresses) == 0
+}
+
+func matchTopics(filter [][]common.Hash, topics []common.Hash) bool {
+	for i, options := range filter {
+		if len(options) == 0 {
+			continue
+		}
+		if i >= len(topics) {
+			return false
+		}
+		matched := false
+		for _, option := range options {
+			matched = matched || option == topics[i]
+		}
+		if !matched {
+			return false
+		}
+	}
+	return true
+}
+
+// encodeRevertReason returns `Error(string)` revert data of reason.
+func encodeRevertReason(t *testing.T, reason string) []byte {
+	stringType, err := gethabi.NewType("string", "", nil)
+	require.NoError(t, err)
+	data, err := gethabi.Arguments{{Type: stringType}}.Pack(reason)
+	require.NoError(t, err)
+	return append(common.FromHex("0x08c379a0"), data...)
+}
+
+func Test_GetUserOperationReceipt(t *testing.T) {
+	entryPoint := common.HexToAddress("0x8A42F70047a99298822dD1dbA34b454fc49913F2")
+	sender := common.HexToAddress("0x7f477B448FA08E8801c7fe44546e6aEae9Daae19")
+	requestID := common.HexToHash("0x01")
+	otherRequestID := common.HexToHash("0x02")
+	txHash := common.HexToHash("0xaa")
+
+	entrypointABI, err := abi.EntryPointMetaData.GetAbi()
+	require.NoError(t, err)
+	operationEvent := func(requestID common.Hash, block uint64, index uint, success bool) types.Log {
+		event := entrypointABI.Events["UserOperationEvent"]
+		data, err := event.Inputs.NonIndexed().Pack(big.NewInt(4), big.NewInt(1000), big.NewInt(10), success)
+		require.NoError(t, err)
+		return types.Log{
+			Address:     entryPoint,
+			Topics:      []common.Hash{event.ID, requestID, common.BytesToHash(sender.Bytes()), {}},
+			Data:        data,
+			BlockNumber: block,
+			TxHash:      txHash,
+			Index:       index,
+		}
+	}
+	revertEvent := func(requestID common.Hash, index uint, reason string) types.Log {
+		event := entrypointABI.Events["UserOperationRevertReason"]
+		data, err := event.Inputs.NonIndexed().Pack(big.NewInt(4), encodeRevertReason(t, reason))
+		require.NoError(t, err)
+		return types.Log{
+			Address:     entryPoint,
+			Topics:      []common.Hash{event.ID, requestID, common.BytesToHash(sender.Bytes())},
+			Data:        data,
+			BlockNumber: 950,
+			TxHash:      txHash,
+			Index:       index,
+		}
+	}
+	before_each := func(t *testing.T, logs ...types.Log) (*Chain, *fakeLogNode) {
+		node := &fakeLogNode{head: 1000, logs: logs}
+		server := rpc.NewServer()
+		require.NoError(t, server.RegisterName("eth", node))
+		httpServer := httptest.NewServer(server)
+		t.Cleanup(httpServer.Close)
+
+		chain, err := NewChain(&config.ChainConfig{
+			ChainID:                   "80001",
+			RPCServer:                 httpServer.URL,
+			EntrypointContractAddress: entryPoint.Hex(),
+			LogLookbackBlocks:         100,
+		})
+		require.NoError(t, err)
+		return chain, node
+	}
+	ctx := context.Background()
+
+	t.Run("event within lookback window", func(t *testing.T) {
+		chain, node := before_each(t, operationEvent(otherRequestID, 920, 0, true), operationEvent(requestID, 950, 1, true))
+
+		receipt, err := chain.GetUserOperationReceipt(ctx, requestID)
+		require.NoError(t, err)
+		require.NotNil(t, receipt)
+		require.Equal(t, []uint64{900}, node.fromBlocks)
+		require.Equal(t, requestID, receipt.RequestID)
+		require.Equal(t, sender, receipt.Sender)
+		require.EqualValues(t, 4, receipt.Nonce.Int64())
+		require.EqualValues(t, 1000, receipt.ActualGasCost.Int64())
+		require.True(t, receipt.Success)
+		require.Empty(t, receipt.Reason)
+		require.Equal(t, txHash, receipt.Receipt.TxHash)
+	})
+
+	t.Run("event before lookback window", func(t *testing.T) {
+		chain, _ := before_each(t, operationEvent(requestID, 899, 0, true))
+
+		receipt, err := chain.GetUserOperationReceipt(ctx, requestID)
+		require.NoError(t, err)
+		require.Nil(t, receipt)
+	})
+
+	t.Run("revert reason of matching event", func(t *testing.T) {
+		chain, _ := before_each(t,
+			revertEvent(otherRequestID, 0, "other operation"),
+			operationEvent(otherRequestID, 950, 1, false),
+			revertEvent(requestID, 2, "ERC20: transfer amount exceeds balance"),
+			operationEvent(requestID, 950, 3, false),
+		)
+
+		receipt, err := chain.GetUserOperationReceipt(ctx, requestID)
+		require.NoError(t, err)
+		require.NotNil(t, receipt)
+		require.False(t, receipt.Success)
+		require.Equal(t, "ERC20: transfer amount exceeds balance", receipt.Reason)
+
+		receipt, err = chain.GetUserOperationReceipt(ctx, otherRequestID)
+		require.NoError(t, err)
+		require.Equal(t, "other operation", receipt.Reason)
+	})
+
+	t.Run("revert reason from other contract is ignored", func(t *testing.T) {
+		otherContract := revertEvent(requestID, 0, "other contract")
+		otherContract.Address = common.HexToAddress("0xc0")
+		chain, _ := before_each(t, otherContract, operationEvent(requestID, 950, 1, false))
+
+		receipt, err := chain.GetUserOperationReceipt(ctx, requestID)
+		require.NoError(t, err)
+		require.False(t, receipt.Success)
+		require.Empty(t, receipt.Reason)
+	})
+
+	t.Run("revert reason after event is ignored", func(t *testing.T) {
+		chain, _ := before_each(t,
+			operationEvent(requestID, 950, 0, true),
+			revertEvent(requestID, 1, "later"),
+		)
+
+		receipt, err := chain.GetUserOperationReceipt(ctx, requestID)
+		require.NoError(t, err)
+		require.True(t, receipt.Success)
+		require.Empty(t, receipt.Reason)
+	})
+}
+
+func Test_decodeRevertReason(t *testing.T) {
+	t.Run("Error(string)", func(t *testing.T) {
+		// Error("ERC20: transfer amount exceeds balance")
+		data := common.FromHex("0x08c379a0" +
+			"0000000000000000000000000000000000000000000000000000000000000020" +
+			"0000000000000000000000000000000000000000000000000000000000000026" +
+			"45524332303a207472616e7366657220616d6f756e7420657863656564732062" +
+			"616c616e63650000000000000000000000000000000000000000000000000000")
+		require.Equal(t, "ERC20: transfer amount exceeds balance", decodeRevertReason(data))
+	})
+
+	t.Run("custom data", func(t *testing.T) {
+		require.Equal(t, "0xdeadbeef", decodeRevertReason(common.FromHex("0xdeadbeef")))
+	})
+}
@@ -0,0 +1,62 @@
+package eth
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"golang.org/x/xerrors"
+
+	"bundler/abi"
+)
+
+// Index of `signature` offset in the head of ABI-encoded UserOperation.
+const signatureHeadIndex = 11
+
+// PackUserOperation packs op the same way as `UserOperationLib.pack`:
+// ABI-encoded op up to, but not including, its signature.
+func PackUserOperation(op abi.UserOperation) ([]byte, error) {
+	packed, err := packUserOperation(op)
+	if err != nil {
+		return nil, err
+	}
+	// `signature` is the last dynamic field, so its data starts right after everything else.
+	// Offset points to the length word of signature, which `pack` excludes as well.
+	offset := new(big.Int).SetBytes(packed[signatureHeadIndex*32 : (signatureHeadIndex+1)*32])
+	if !offset.IsUint64() || offset.Uint64() > uint64(len(packed)) {
+		return nil, xerrors.Errorf("invalid signature offset %s", offset.String())
+	}
+	return packed[:offset.Uint64()], nil
+}
+
+// HashUserOperation returns `UserOperationLib.hash` of op, which does not cover signature.
+func HashUserOperation(op abi.UserOperation) (common.Hash, error) {
+	packed, err := PackUserOperation(op)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return crypto.Keccak256Hash(packed), nil
+}
+
+// RequestID computes `EntryPoint.getRequestId` off-chain:
+// `keccak256(abi.encode(hash(op), entryPoint, chainID))`.
+func RequestID(op abi.UserOperation, entryPoint common.Address, chainID *big.Int) (common.Hash, error) {
+	hash, err := HashUserOperation(op)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	if chainID.Sign() < 0 || chainID.BitLen() > 256 {
+		return common.Hash{}, xerrors.Errorf("invalid chain ID %s", chainID.String())
+	}
+
+	encoded := make([]byte, 0, 3*32)
+	encoded = append(encoded, hash.Bytes()...)
+	encoded = append(encoded, common.LeftPadBytes(entryPoint.Bytes(), 32)...)
+	encoded = append(encoded, common.LeftPadBytes(chainID.Bytes(), 32)...)
+	return crypto.Keccak256Hash(encoded), nil
+}
+
+// RequestID computes request ID of op on this entry point, without calling the contract.
+func (e *EntryPoint) RequestID(op abi.UserOperation) (common.Hash, error) {
+	return RequestID(op, e.Address, e.ID)
+}
@@ -0,0 +1,109 @@
+package eth
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+
+	"bundler/abi"
+)
+
+func Test_PackUserOperation(t *testing.T) {
+	op := emptyOperation()
+	op.Nonce = big.NewInt(4)
+	op.InitCode = common.FromHex("0x01020304")
+	op.CallData = common.FromHex("0x80c5c7d0")
+	op.CallGas = big.NewInt(89128)
+	op.PaymasterData = make([]byte, 33)
+	op.Signature = common.FromHex("0xe0dd")
+
+	t.Run("same as packUserOp for signature", func(t *testing.T) {
+		// `packUserOp(op, true)` in `test/utils.ts` encodes op with empty signature,
+		// then strips the zero length word of signature.
+		unsigned := op
+		unsigned.Signature = []byte{}
+		encoded, err := packUserOperation(unsigned)
+		require.NoError(t, err)
+
+		packed, err := PackUserOperation(op)
+		require.NoError(t, err)
+		require.Equal(t, encoded[:len(encoded)-32], packed)
+	})
+
+	t.Run("signature is not covered", func(t *testing.T) {
+		hash, err := HashUserOperation(op)
+		require.NoError(t, err)
+
+		other := op
+		other.Signature = make([]byte, 65)
+		otherHash, err := HashUserOperation(other)
+		require.NoError(t, err)
+		require.Equal(t, hash, otherHash)
+
+		other.Nonce = big.NewInt(5)
+		otherHash, err = HashUserOperation(other)
+		require.NoError(t, err)
+		require.NotEqual(t, hash, otherHash)
+	})
+}
+
+func Test_RequestID(t *testing.T) {
+	op := emptyOperation()
+	entryPoint := common.HexToAddress("0x8A42F70047a99298822dD1dbA34b454fc49913F2")
+
+	t.Run("depends on entry point and chain ID", func(t *testing.T) {
+		requestID, err := RequestID(op, entryPoint, big.NewInt(80001))
+		require.NoError(t, err)
+
+		otherChain, err := RequestID(op, entryPoint, big.NewInt(137))
+		require.NoError(t, err)
+		require.NotEqual(t, requestID, otherChain)
+
+		otherEntryPoint, err := RequestID(op, common.HexToAddress("0x01"), big.NewInt(80001))
+		require.NoError(t, err)
+		require.NotEqual(t, requestID, otherEntryPoint)
+	})
+
+	t.Run("fixed vector", func(t *testing.T) {
+		// Expected values are computed outside of Go, encoding op by hand the same way as
+		// `packUserOp(op, true)` and `getRequestId` of `test/utils.ts`, which match `EntryPoint.getRequestId`.
+		op := abi.UserOperation{
+			Sender:               common.HexToAddress("0x7f477B448FA08E8801c7fe44546e6aEae9Daae19"),
+			Nonce:                big.NewInt(4),
+			InitCode:             common.FromHex("0x01020304"),
+			CallData:             common.FromHex("0x80c5c7d0000000000000000000000000000000000000000000000000000000000000c0"),
+			CallGas:              big.NewInt(89128),
+			VerificationGas:      big.NewInt(60000),
+			PreVerificationGas:   big.NewInt(21000),
+			MaxFeePerGas:         big.NewInt(1500000000),
+			MaxPriorityFeePerGas: big.NewInt(1000000000),
+			PaymasterData:        make([]byte, 33),
+			Signature:            common.FromHex("0xe0dd"),
+		}
+		for i := range op.PaymasterData {
+			op.PaymasterData[i] = byte(i)
+		}
+
+		packed, err := PackUserOperation(op)
+		require.NoError(t, err)
+		require.Len(t, packed, 640)
+		hash, err := HashUserOperation(op)
+		require.NoError(t, err)
+		require.Equal(t, common.HexToHash("0x50123a024841ea81068bda018b849a9ddd554b4f43cc10aec3568095df3d8654"), hash)
+		requestID, err := RequestID(op, entryPoint, big.NewInt(80001))
+		require.NoError(t, err)
+		require.Equal(t, common.HexToHash("0xcb432201802c56f039749132d3bc69de21cb0e68842b20020073fe0edb530615"), requestID)
+	})
+
+	t.Run("entry point of chain", func(t *testing.T) {
+		chain := &Chain{ID: big.NewInt(80001)}
+		expected, err := RequestID(op, entryPoint, chain.ID)
+		require.NoError(t, err)
+
+		actual, err := (&EntryPoint{Chain: chain, Address: entryPoint}).RequestID(op)
+		require.NoError(t, err)
+		require.Equal(t, expected, actual)
+	})
+}
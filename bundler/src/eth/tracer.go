@@ -0,0 +1,315 @@
+package eth
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"golang.org/x/xerrors"
+
+	"bundler/abi"
+)
+
+// Entities whose validation code is traced.
+const (
+	EntityWallet    = "wallet"
+	EntityPaymaster = "paymaster"
+)
+
+// bannedOpcodes must not be used during validation, since their results
+// differ between simulation and on-chain execution.
+// `GAS` is allowed only if immediately followed by `CALL`, `CALLCODE`, `DELEGATECALL` or `STATICCALL`.
+var bannedOpcodes = map[string]bool{
+	"GASPRICE":     true,
+	"GASLIMIT":     true,
+	"DIFFICULTY":   true,
+	"TIMESTAMP":    true,
+	"BASEFEE":      true,
+	"BLOCKHASH":    true,
+	"NUMBER":       true,
+	"SELFBALANCE":  true,
+	"BALANCE":      true,
+	"ORIGIN":       true,
+	"GAS":          true,
+	"CREATE":       true,
+	"COINBASE":     true,
+	"SELFDESTRUCT": true,
+}
+
+// validationTracer is a geth JS tracer for `simulateValidation`.
+// Code at depth 1 is the EntryPoint itself. The `NUMBER` opcode at depth 1
+// marks the end of wallet validation and the start of paymaster validation.
+// For each phase it counts opcodes and records storage slots accessed by each contract.
+// Preimages of `KECCAK256` are recorded to recognize mapping slots.
+const validationTracer = `{
+	phase: 0,
+	phases: [{opcodes: {}, storage: {}}, {opcodes: {}, storage: {}}, {opcodes: {}, storage: {}}],
+	keccak: [],
+	numberMarkers: 0,
+	gasPending: false,
+	// GAS is allowed only right before one of these.
+	callOpcodes: {CALL: true, CALLCODE: true, DELEGATECALL: true, STATICCALL: true},
+	count: function(op) {
+		var opcodes = this.phases[this.phase].opcodes;
+		opcodes[op] = (opcodes[op] || 0) + 1;
+	},
+	access: function(log, mode) {
+		var storage = this.phases[this.phase].storage;
+		var addr = toHex(log.contract.getAddress());
+		var slot = "0x" + log.stack.peek(0).toString(16);
+		if (!storage[addr]) {
+			storage[addr] = {};
+		}
+		if (storage[addr][slot] !== "write") {
+			storage[addr][slot] = mode;
+		}
+	},
+	// Memory is expanded after the opcode runs, the part not expanded yet is zero.
+	hashed: function(log) {
+		var offset = log.stack.peek(0).valueOf();
+		var size = log.stack.peek(1).valueOf();
+		if (size === 0 || size > 1024) {
+			return;
+		}
+		var end = Math.min(offset + size, log.memory.length());
+		var preimage = offset < end ? toHex(log.memory.slice(offset, end)) : "0x";
+		for (var i = Math.max(end, offset); i < offset + size; i++) {
+			preimage += "00";
+		}
+		this.keccak.push(preimage);
+	},
+	step: function(log, db) {
+		var op = log.op.toString();
+		var depth = log.getDepth();
+		if (this.gasPending) {
+			this.gasPending = false;
+			if (!this.callOpcodes[op]) {
+				this.count("GAS");
+			}
+		}
+		if (op === "KECCAK256" || op === "SHA3") {
+			this.hashed(log);
+		}
+		if (depth === 1) {
+			if (op === "NUMBER") {
+				this.numberMarkers++;
+				if (this.phase < 2) {
+					this.phase++;
+				}
+			}
+			return;
+		}
+		if (op === "GAS") {
+			this.gasPending = true;
+			return;
+		}
+		if (op === "SLOAD") {
+			this.access(log, "read");
+		} else if (op === "SSTORE") {
+			this.access(log, "write");
+		}
+		this.count(op);
+	},
+	fault: function(log, db) {},
+	result: function(ctx, db) {
+		return {
+			phases: this.phases,
+			keccak: this.keccak,
+			numberMarkers: this.numberMarkers,
+			error: ctx.error || ""
+		};
+	}
+}`
+
+type validationPhase struct {
+	Opcodes map[string]int `json:"opcodes"`
+	// Contract address => slot in hex => "read" or "write".
+	Storage map[common.Address]map[string]string `json:"storage"`
+}
+
+type validationTrace struct {
+	// Phases of [wallet, paymaster, after paymaster].
+	Phases        []validationPhase `json:"phases"`
+	Keccak        []hexutil.Bytes   `json:"keccak"`
+	NumberMarkers int               `json:"numberMarkers"`
+	Error         string            `json:"error"`
+}
+
+// BannedOpcodeError means wallet or paymaster used an opcode forbidden in validation.
+type BannedOpcodeError struct {
+	Entity string
+	Opcode string
+}
+
+func (e *BannedOpcodeError) Error() string {
+	return fmt.Sprintf("%s uses banned opcode %s during validation", e.Entity, e.Opcode)
+}
+
+// StorageAccessError means wallet or paymaster accessed storage not associated with it during validation.
+type StorageAccessError struct {
+	Entity  string
+	Address common.Address
+	Slot    *big.Int
+	// "read" or "write"
+	Access string
+}
+
+func (e *StorageAccessError) Error() string {
+	return fmt.Sprintf("%s %ss storage slot 0x%x of %s during validation", e.Entity, e.Access, e.Slot, e.Address.Hex())
+}
+
+// maxMappingOffset is how many slots after `keccak256(key || slot)` are considered
+// part of a mapping value, so that struct values can be accessed.
+const maxMappingOffset = 128
+
+// traceCall runs `debug_traceCall` with `validationTracer` from zero address.
+func (c *Chain) traceCall(ctx context.Context, to common.Address, data []byte) (*validationTrace, error) {
+	callArgs := map[string]any{
+		"from": common.Address{},
+		"to":   to,
+		"data": hexutil.Bytes(data),
+	}
+	traceConfig := map[string]any{
+		"tracer": validationTracer,
+	}
+
+	trace := &validationTrace{}
+	if err := c.rpcClient.CallContext(ctx, trace, "debug_traceCall", callArgs, "latest", traceConfig); err != nil {
+		return nil, xerrors.Errorf("failed to trace call: %w", err)
+	}
+	return trace, nil
+}
+
+// mappingSlots returns `keccak256(key || ...)` of every hashed preimage starting with key,
+// i.e. base slots of mapping values keyed by key.
+func mappingSlots(trace *validationTrace, key common.Address) []*big.Int {
+	padded := common.LeftPadBytes(key.Bytes(), 32)
+	slots := []*big.Int{}
+	for _, preimage := range trace.Keccak {
+		if len(preimage) >= 32 && bytes.Equal(preimage[:32], padded) {
+			slots = append(slots, crypto.Keccak256Hash(preimage).Big())
+		}
+	}
+	return slots
+}
+
+func isMappingSlot(slot *big.Int, bases []*big.Int) bool {
+	for _, base := range bases {
+		offset := big.NewInt(0).Sub(slot, base)
+		if offset.Sign() >= 0 && offset.Cmp(big.NewInt(maxMappingOffset)) < 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func containsSlot(slot *big.Int, slots []*big.Int) bool {
+	for _, s := range slots {
+		if s.Cmp(slot) == 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// checkValidationTrace rejects op if wallet or paymaster used banned opcodes or storage.
+// During validation, wallet and paymaster can only access:
+//   - their own storage,
+//   - their cells in EntryPoint given by `getSenderStorage`, as `entrypointCells`,
+//   - mapping slots keyed by sender in any other contract, e.g. ERC20 balance of the wallet.
+func checkValidationTrace(trace *validationTrace, op abi.UserOperation, entrypoint common.Address, entrypointCells map[common.Address][]*big.Int) error {
+	if trace.Error != "" {
+		return xerrors.Errorf("validation reverted in trace: %s", trace.Error)
+	}
+	if trace.NumberMarkers < 1 || len(trace.Phases) < 2 {
+		return xerrors.Errorf("NUMBER marker not found in trace, is entrypoint contract address correct?")
+	}
+
+	entities := []string{EntityWallet, EntityPaymaster}
+	for phase, entity := range entities {
+		for opcode := range trace.Phases[phase].Opcodes {
+			if bannedOpcodes[opcode] {
+				return &BannedOpcodeError{Entity: entity, Opcode: opcode}
+			}
+		}
+	}
+
+	// CREATE2 is only allowed once, to deploy the wallet from `initCode`.
+	allowedCreate2 := 0
+	if len(op.InitCode) > 0 {
+		allowedCreate2 = 1
+	}
+	if trace.Phases[0].Opcodes["CREATE2"] > allowedCreate2 {
+		return &BannedOpcodeError{Entity: EntityWallet, Opcode: "CREATE2"}
+	}
+	if trace.Phases[1].Opcodes["CREATE2"] > 0 {
+		return &BannedOpcodeError{Entity: EntityPaymaster, Opcode: "CREATE2"}
+	}
+
+	senderSlots := mappingSlots(trace, op.Sender)
+	entityAddresses := []common.Address{op.Sender, op.Paymaster}
+	for phase, entity := range entities {
+		self := entityAddresses[phase]
+		for address, slots := range trace.Phases[phase].Storage {
+			if address == self {
+				continue
+			}
+			for hexSlot, access := range slots {
+				slot, ok := big.NewInt(0).SetString(strings.TrimPrefix(hexSlot, "0x"), 16)
+				if !ok {
+					return xerrors.Errorf("invalid storage slot %s in trace", hexSlot)
+				}
+				if address == entrypoint && containsSlot(slot, entrypointCells[self]) {
+					continue
+				}
+				if address != entrypoint && isMappingSlot(slot, senderSlots) {
+					continue
+				}
+				return &StorageAccessError{Entity: entity, Address: address, Slot: slot, Access: access}
+			}
+		}
+	}
+	return nil
+}
+
+// TraceValidation runs `simulateValidation` under `debug_traceCall`,
+// and rejects op if wallet or paymaster used banned opcodes or storage.
+// The RPC server must support geth JS tracers.
+func (e *EntryPoint) TraceValidation(ctx context.Context, op abi.UserOperation) error {
+	entrypoint, err := abi.NewEntryPoint(e.Address, e.client)
+	if err != nil {
+		return err
+	}
+	entrypointCells := map[common.Address][]*big.Int{}
+	for _, address := range []common.Address{op.Sender, op.Paymaster} {
+		if address == (common.Address{}) {
+			continue
+		}
+		cells, err := entrypoint.GetSenderStorage(&bind.CallOpts{Context: ctx}, address)
+		if err != nil {
+			return xerrors.Errorf("failed to get sender storage of %s: %w", address.Hex(), err)
+		}
+		entrypointCells[address] = cells
+	}
+
+	entrypointABI, err := abi.EntryPointMetaData.GetAbi()
+	if err != nil {
+		return err
+	}
+	data, err := entrypointABI.Pack("simulateValidation", op)
+	if err != nil {
+		return xerrors.Errorf("failed to pack simulateValidation call: %w", err)
+	}
+
+	trace, err := e.traceCall(ctx, e.Address, data)
+	if err != nil {
+		return err
+	}
+	return checkValidationTrace(trace, op, e.Address, entrypointCells)
+}
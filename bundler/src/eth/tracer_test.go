@@ -0,0 +1,276 @@
+package eth
+
+import (
+	"bundler/abi"
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/core/vm/runtime"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/eth/tracers"
+	_ "github.com/ethereum/go-ethereum/eth/tracers/js"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	testEntryPoint = common.HexToAddress("0xe0")
+	testWallet     = common.HexToAddress("0xa0")
+	testPaymaster  = common.HexToAddress("0xb0")
+	testToken      = common.HexToAddress("0xc0")
+)
+
+// callCode makes a CALL with all-zero value and memory, forwarding all gas.
+func callCode(to common.Address) []byte {
+	code := []byte{0x60, 0x00, 0x60, 0x00, 0x60, 0x00, 0x60, 0x00, 0x60, 0x00, 0x73}
+	code = append(code, to.Bytes()...)
+	return append(code, 0x5a, 0xf1, 0x50) // GAS CALL POP
+}
+
+// entryPointCode calls wallet, then NUMBER, then paymaster, like `simulateValidation`.
+func entryPointCode() []byte {
+	code := append(callCode(testWallet), 0x43, 0x50) // NUMBER POP
+	return append(append(code, callCode(testPaymaster)...), 0x00)
+}
+
+// runValidationTracer runs validationTracer in an in-memory EVM on entryPointCode with wallet and paymaster code.
+func runValidationTracer(t *testing.T, walletCode, paymasterCode []byte) *validationTrace {
+	return runTracer(t, map[common.Address][]byte{testWallet: walletCode, testPaymaster: paymasterCode})
+}
+
+// runTracer runs validationTracer in an in-memory EVM on entryPointCode, with code of other contracts in codes.
+func runTracer(t *testing.T, codes map[common.Address][]byte) *validationTrace {
+	statedb, err := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	require.NoError(t, err)
+	statedb.SetCode(testEntryPoint, entryPointCode())
+	for address, code := range codes {
+		statedb.SetCode(address, code)
+	}
+
+	tracer, err := tracers.New(validationTracer, &tracers.Context{}, nil)
+	require.NoError(t, err)
+	_, _, err = runtime.Call(testEntryPoint, nil, &runtime.Config{
+		State:     statedb,
+		EVMConfig: vm.Config{Debug: true, Tracer: tracer},
+	})
+	require.NoError(t, err)
+
+	result, err := tracer.GetResult()
+	require.NoError(t, err)
+	trace := &validationTrace{}
+	require.NoError(t, json.Unmarshal(result, trace))
+	return trace
+}
+
+func phase(opcodes map[string]int) validationPhase {
+	return validationPhase{Opcodes: opcodes, Storage: map[common.Address]map[string]string{}}
+}
+
+func Test_checkValidationTrace(t *testing.T) {
+	trace := func(wallet, paymaster validationPhase) *validationTrace {
+		return &validationTrace{
+			Phases:        []validationPhase{wallet, paymaster, phase(map[string]int{})},
+			NumberMarkers: 1,
+		}
+	}
+	check := func(tr *validationTrace, op abi.UserOperation) error {
+		return checkValidationTrace(tr, op, testEntryPoint, map[common.Address][]*big.Int{
+			testWallet:    {big.NewInt(100)},
+			testPaymaster: {big.NewInt(200)},
+		})
+	}
+	op := emptyOperation()
+	op.Sender = testWallet
+	op.Paymaster = testPaymaster
+
+	t.Run("Clean", func(t *testing.T) {
+		err := check(trace(phase(map[string]int{"SLOAD": 2, "CALL": 1}), phase(map[string]int{"SLOAD": 1})), op)
+		require.NoError(t, err)
+	})
+
+	t.Run("Wallet uses TIMESTAMP", func(t *testing.T) {
+		err := check(trace(phase(map[string]int{"TIMESTAMP": 1}), phase(map[string]int{})), op)
+		bannedErr := &BannedOpcodeError{}
+		require.ErrorAs(t, err, &bannedErr)
+		require.Equal(t, EntityWallet, bannedErr.Entity)
+		require.Equal(t, "TIMESTAMP", bannedErr.Opcode)
+	})
+
+	t.Run("Paymaster uses GASPRICE", func(t *testing.T) {
+		err := check(trace(phase(map[string]int{}), phase(map[string]int{"GASPRICE": 1})), op)
+		bannedErr := &BannedOpcodeError{}
+		require.ErrorAs(t, err, &bannedErr)
+		require.Equal(t, EntityPaymaster, bannedErr.Entity)
+	})
+
+	t.Run("Opcodes after paymaster validation are ignored", func(t *testing.T) {
+		tr := trace(phase(map[string]int{}), phase(map[string]int{}))
+		tr.Phases[2].Opcodes["TIMESTAMP"] = 1
+		require.NoError(t, check(tr, op))
+	})
+
+	t.Run("CREATE2 only with initCode", func(t *testing.T) {
+		op := op
+		require.Error(t, check(trace(phase(map[string]int{"CREATE2": 1}), phase(map[string]int{})), op))
+
+		op.InitCode = []byte{0x01}
+		require.NoError(t, check(trace(phase(map[string]int{"CREATE2": 1}), phase(map[string]int{})), op))
+		require.Error(t, check(trace(phase(map[string]int{"CREATE2": 2}), phase(map[string]int{})), op))
+	})
+
+	t.Run("GAS before CALL is allowed", func(t *testing.T) {
+		tr := runValidationTracer(t, callCode(common.HexToAddress("0xd0")), []byte{0x00})
+		require.Zero(t, tr.Phases[0].Opcodes["GAS"])
+		require.NoError(t, check(tr, op))
+	})
+
+	t.Run("GAS before CALLER is banned", func(t *testing.T) {
+		// GAS CALLER POP POP STOP
+		tr := runValidationTracer(t, []byte{0x5a, 0x33, 0x50, 0x50, 0x00}, []byte{0x00})
+		bannedErr := &BannedOpcodeError{}
+		require.ErrorAs(t, check(tr, op), &bannedErr)
+		require.Equal(t, EntityWallet, bannedErr.Entity)
+		require.Equal(t, "GAS", bannedErr.Opcode)
+	})
+
+	t.Run("No NUMBER marker", func(t *testing.T) {
+		err := check(&validationTrace{Phases: []validationPhase{phase(nil), phase(nil), phase(nil)}}, op)
+		require.Error(t, err)
+	})
+
+	t.Run("Own storage", func(t *testing.T) {
+		tr := trace(phase(map[string]int{}), phase(map[string]int{}))
+		tr.Phases[0].Storage[testWallet] = map[string]string{"0x0": "write"}
+		tr.Phases[1].Storage[testPaymaster] = map[string]string{"0x1": "read"}
+		require.NoError(t, check(tr, op))
+	})
+
+	t.Run("EntryPoint storage", func(t *testing.T) {
+		tr := trace(phase(map[string]int{}), phase(map[string]int{}))
+		tr.Phases[0].Storage[testEntryPoint] = map[string]string{"0x64": "write"}
+		tr.Phases[1].Storage[testEntryPoint] = map[string]string{"0xc8": "read"}
+		require.NoError(t, check(tr, op))
+
+		// Paymaster cannot touch deposit of wallet.
+		tr.Phases[1].Storage[testEntryPoint]["0x64"] = "read"
+		storageErr := &StorageAccessError{}
+		require.ErrorAs(t, check(tr, op), &storageErr)
+		require.Equal(t, EntityPaymaster, storageErr.Entity)
+		require.Equal(t, testEntryPoint, storageErr.Address)
+		require.EqualValues(t, 100, storageErr.Slot.Int64())
+	})
+
+	t.Run("Mapping slot keyed by sender", func(t *testing.T) {
+		preimage := append(common.LeftPadBytes(testWallet.Bytes(), 32), make([]byte, 32)...)
+		base := crypto.Keccak256Hash(preimage).Big()
+
+		tr := trace(phase(map[string]int{}), phase(map[string]int{}))
+		tr.Keccak = []hexutil.Bytes{preimage}
+		tr.Phases[0].Storage[testToken] = map[string]string{
+			hexutil.EncodeBig(base):                                 "read",
+			hexutil.EncodeBig(big.NewInt(0).Add(base, common.Big1)): "write",
+		}
+		tr.Phases[1].Storage[testToken] = map[string]string{hexutil.EncodeBig(base): "write"}
+		require.NoError(t, check(tr, op))
+
+		tr.Phases[0].Storage[testToken]["0x0"] = "read"
+		storageErr := &StorageAccessError{}
+		require.ErrorAs(t, check(tr, op), &storageErr)
+		require.Equal(t, EntityWallet, storageErr.Entity)
+		require.Equal(t, testToken, storageErr.Address)
+		require.Equal(t, "read", storageErr.Access)
+	})
+}
+
+// Runs validationTracer in an in-memory EVM, the same as `debug_traceCall` of geth.
+func Test_validationTracer(t *testing.T) {
+	call := callCode
+	// Reads `balances[msg.sender]`, where `mapping(address => uint256) balances` is at slot 0.
+	tokenCode := []byte{
+		0x33, 0x60, 0x00, 0x52, // MSTORE(0, CALLER)
+		0x60, 0x40, 0x60, 0x00, 0x20, // KECCAK256(0, 64)
+		0x54, 0x50, 0x00, // SLOAD POP STOP
+	}
+
+	run := func(walletCode, paymasterCode []byte) *validationTrace {
+		return runTracer(t, map[common.Address][]byte{
+			testWallet:    walletCode,
+			testPaymaster: paymasterCode,
+			testToken:     tokenCode,
+		})
+	}
+	op := emptyOperation()
+	op.Sender = testWallet
+	op.Paymaster = testPaymaster
+	check := func(trace *validationTrace) error {
+		return checkValidationTrace(trace, op, testEntryPoint, map[common.Address][]*big.Int{})
+	}
+
+	t.Run("Clean", func(t *testing.T) {
+		// SLOAD(0) POP STOP
+		trace := run([]byte{0x60, 0x00, 0x54, 0x50, 0x00}, []byte{0x00})
+		require.Equal(t, 1, trace.NumberMarkers)
+		require.Equal(t, 1, trace.Phases[0].Opcodes["SLOAD"])
+		require.Equal(t, "read", trace.Phases[0].Storage[testWallet]["0x0"])
+		require.NoError(t, check(trace))
+	})
+
+	t.Run("Wallet uses TIMESTAMP", func(t *testing.T) {
+		// TIMESTAMP POP STOP
+		trace := run([]byte{0x42, 0x50, 0x00}, []byte{0x00})
+		bannedErr := &BannedOpcodeError{}
+		require.ErrorAs(t, check(trace), &bannedErr)
+		require.Equal(t, EntityWallet, bannedErr.Entity)
+		require.Equal(t, "TIMESTAMP", bannedErr.Opcode)
+	})
+
+	t.Run("Paymaster uses BALANCE", func(t *testing.T) {
+		// ADDRESS BALANCE POP STOP
+		trace := run([]byte{0x00}, []byte{0x30, 0x31, 0x50, 0x00})
+		bannedErr := &BannedOpcodeError{}
+		require.ErrorAs(t, check(trace), &bannedErr)
+		require.Equal(t, EntityPaymaster, bannedErr.Entity)
+		require.Equal(t, "BALANCE", bannedErr.Opcode)
+	})
+
+	t.Run("GAS before CALL is allowed", func(t *testing.T) {
+		trace := run(call(common.HexToAddress("0xd0")), []byte{0x5a, 0x50, 0x00})
+		require.Zero(t, trace.Phases[0].Opcodes["GAS"])
+		require.Equal(t, 1, trace.Phases[1].Opcodes["GAS"])
+	})
+
+	t.Run("Wallet reads its own token balance", func(t *testing.T) {
+		trace := run(call(testToken), []byte{0x00})
+		require.Len(t, trace.Keccak, 1)
+		require.NoError(t, check(trace))
+	})
+
+	t.Run("Paymaster reads its own token balance", func(t *testing.T) {
+		// Key is paymaster, not associated with sender.
+		trace := run([]byte{0x00}, call(testToken))
+		storageErr := &StorageAccessError{}
+		require.ErrorAs(t, check(trace), &storageErr)
+		require.Equal(t, EntityPaymaster, storageErr.Entity)
+		require.Equal(t, testToken, storageErr.Address)
+	})
+
+	t.Run("Wallet writes storage of another contract", func(t *testing.T) {
+		// SSTORE(0, 1) STOP
+		otherCode := []byte{0x60, 0x01, 0x60, 0x00, 0x55, 0x00}
+		other := common.HexToAddress("0xd0")
+		trace := runTracer(t, map[common.Address][]byte{
+			testWallet:    call(other),
+			testPaymaster: {0x00},
+			other:         otherCode,
+		})
+		storageErr := &StorageAccessError{}
+		require.ErrorAs(t, check(trace), &storageErr)
+		require.Equal(t, EntityWallet, storageErr.Entity)
+		require.Equal(t, "write", storageErr.Access)
+	})
+}
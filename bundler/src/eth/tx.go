@@ -0,0 +1,145 @@
+package eth
+
+import (
+	"context"
+	"errors"
+	"math/big"
+
+	ethereum "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"golang.org/x/xerrors"
+)
+
+// Fees of a re-sent transaction are raised by 12.5%.
+// Nodes reject a replacement transaction paying less than 10% more.
+const (
+	resendFeeBumpNumerator   = 9
+	resendFeeBumpDenominator = 8
+)
+
+func (c *Chain) BlockNumber(ctx context.Context) (uint64, error) {
+	return c.client.BlockNumber(ctx)
+}
+
+// NonceAt returns nonce of account at latest block.
+func (c *Chain) NonceAt(ctx context.Context, account common.Address) (uint64, error) {
+	return c.client.NonceAt(ctx, account, nil)
+}
+
+// TransactionReceipt returns receipt and effective gas price of tx.
+// Receipt is nil if tx is not mined yet.
+func (c *Chain) TransactionReceipt(ctx context.Context, tx *types.Transaction) (*types.Receipt, *big.Int, error) {
+	receipt, err := c.client.TransactionReceipt(ctx, tx.Hash())
+	if errors.Is(err, ethereum.NotFound) {
+		return nil, nil, nil
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// `effectiveGasPrice` is not decoded by ethclient.
+	header, err := c.client.HeaderByNumber(ctx, receipt.BlockNumber)
+	if err != nil {
+		return nil, nil, xerrors.Errorf("failed to get block header: %w", err)
+	}
+	return receipt, effectiveGasPrice(tx, header.BaseFee), nil
+}
+
+func effectiveGasPrice(tx *types.Transaction, baseFee *big.Int) *big.Int {
+	if baseFee == nil {
+		return tx.GasPrice()
+	}
+	price := big.NewInt(0).Add(baseFee, tx.GasTipCap())
+	if price.Cmp(tx.GasFeeCap()) > 0 {
+		return tx.GasFeeCap()
+	}
+	return price
+}
+
+// bumpFee raises fee by 12.5%, rounding up, but no lower than market.
+func bumpFee(fee *big.Int, market *big.Int) *big.Int {
+	bumped := big.NewInt(0).Mul(fee, big.NewInt(resendFeeBumpNumerator))
+	bumped.Add(bumped, big.NewInt(resendFeeBumpDenominator-1))
+	bumped.Div(bumped, big.NewInt(resendFeeBumpDenominator))
+	if market != nil && market.Cmp(bumped) > 0 {
+		return big.NewInt(0).Set(market)
+	}
+	return bumped
+}
+
+// bumpTransaction returns unsigned copy of tx with bumped fees.
+// For EIP-1559 transactions, fee cap is kept at least twice the base fee plus tip.
+func bumpTransaction(tx *types.Transaction, gasPrice, tipCap, baseFee *big.Int) types.TxData {
+	to := tx.To()
+	if tx.Type() == types.LegacyTxType {
+		return &types.LegacyTx{
+			Nonce:    tx.Nonce(),
+			GasPrice: bumpFee(tx.GasPrice(), gasPrice),
+			Gas:      tx.Gas(),
+			To:       to,
+			Value:    tx.Value(),
+			Data:     tx.Data(),
+		}
+	}
+
+	tip := bumpFee(tx.GasTipCap(), tipCap)
+	var marketFeeCap *big.Int
+	if baseFee != nil {
+		marketFeeCap = big.NewInt(0).Mul(baseFee, big.NewInt(2))
+		marketFeeCap.Add(marketFeeCap, tip)
+	}
+	feeCap := bumpFee(tx.GasFeeCap(), marketFeeCap)
+	if feeCap.Cmp(tip) < 0 {
+		feeCap = tip
+	}
+	return &types.DynamicFeeTx{
+		ChainID:    tx.ChainId(),
+		Nonce:      tx.Nonce(),
+		GasTipCap:  tip,
+		GasFeeCap:  feeCap,
+		Gas:        tx.Gas(),
+		To:         to,
+		Value:      tx.Value(),
+		Data:       tx.Data(),
+		AccessList: tx.AccessList(),
+	}
+}
+
+// ResendTransaction replaces tx with a transaction of same nonce and calldata, paying bumped fees.
+func (c *Chain) ResendTransaction(ctx context.Context, tx *types.Transaction) (*types.Transaction, error) {
+	var gasPrice, tipCap, baseFee *big.Int
+	var err error
+	if tx.Type() == types.LegacyTxType {
+		gasPrice, err = c.client.SuggestGasPrice(ctx)
+		if err != nil {
+			return nil, xerrors.Errorf("failed to suggest gas price: %w", err)
+		}
+	} else {
+		tipCap, err = c.client.SuggestGasTipCap(ctx)
+		if err != nil {
+			return nil, xerrors.Errorf("failed to suggest gas tip cap: %w", err)
+		}
+		baseFee, err = c.BaseFee(ctx)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	from, err := types.Sender(types.LatestSignerForChainID(c.ID), tx)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to recover sender: %w", err)
+	}
+	bundler, err := c.bundlerSigner(from)
+	if err != nil {
+		return nil, err
+	}
+	replacement, err := bundler.SignTx(ctx, types.NewTx(bumpTransaction(tx, gasPrice, tipCap, baseFee)), c.ID)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to sign replacement transaction: %w", err)
+	}
+	if err := c.client.SendTransaction(ctx, replacement); err != nil {
+		return nil, xerrors.Errorf("failed to send replacement transaction: %w", err)
+	}
+	return replacement, nil
+}
@@ -0,0 +1,42 @@
+package eth
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_bumpTransaction(t *testing.T) {
+	to := common.HexToAddress("0x0a")
+
+	t.Run("EIP-1559", func(t *testing.T) {
+		tx := types.NewTx(&types.DynamicFeeTx{ChainID: big.NewInt(1), Nonce: 7, GasTipCap: big.NewInt(100), GasFeeCap: big.NewInt(1000), Gas: 50000, To: &to, Data: []byte{0x01}})
+
+		bumped := types.NewTx(bumpTransaction(tx, nil, big.NewInt(1), big.NewInt(1)))
+		require.EqualValues(t, 7, bumped.Nonce())
+		require.Equal(t, tx.Data(), bumped.Data())
+		require.EqualValues(t, 113, bumped.GasTipCap().Int64())
+		require.EqualValues(t, 1125, bumped.GasFeeCap().Int64())
+
+		// Market moved higher than 12.5%
+		bumped = types.NewTx(bumpTransaction(tx, nil, big.NewInt(500), big.NewInt(1000)))
+		require.EqualValues(t, 500, bumped.GasTipCap().Int64())
+		require.EqualValues(t, 2500, bumped.GasFeeCap().Int64())
+	})
+
+	t.Run("legacy", func(t *testing.T) {
+		tx := types.NewTx(&types.LegacyTx{Nonce: 7, GasPrice: big.NewInt(100), Gas: 50000, To: &to})
+		bumped := types.NewTx(bumpTransaction(tx, big.NewInt(50), nil, nil))
+		require.Equal(t, uint8(types.LegacyTxType), bumped.Type())
+		require.EqualValues(t, 113, bumped.GasPrice().Int64())
+	})
+}
+
+func Test_effectiveGasPrice(t *testing.T) {
+	tx := types.NewTx(&types.DynamicFeeTx{GasTipCap: big.NewInt(10), GasFeeCap: big.NewInt(100)})
+	require.EqualValues(t, 60, effectiveGasPrice(tx, big.NewInt(50)).Int64())
+	require.EqualValues(t, 100, effectiveGasPrice(tx, big.NewInt(95)).Int64())
+}
@@ -0,0 +1,96 @@
+package eth
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"golang.org/x/xerrors"
+
+	"bundler/abi"
+)
+
+// SignatureError is an operation not signed by owner of its SimpleWallet.
+type SignatureError struct {
+	Owner common.Address
+	// Zero if no signer can be recovered from signature.
+	Signer common.Address
+	// Why no signer can be recovered, if so.
+	Reason string
+}
+
+func (e *SignatureError) Error() string {
+	if e.Reason != "" {
+		return fmt.Sprintf("signature does not match wallet owner %s: %s", e.Owner.Hex(), e.Reason)
+	}
+	return fmt.Sprintf("signature does not match wallet owner %s: signed by %s", e.Owner.Hex(), e.Signer.Hex())
+}
+
+// recoverSigner recovers signer of requestID the same way as
+// `requestId.toEthSignedMessageHash().recover(signature)` of OpenZeppelin ECDSA:
+// 65-byte `(r, s, v)` or 64-byte EIP-2098 `(r, vs)` signature, `v` of 27 or 28, and `s` in lower half order.
+func recoverSigner(requestID common.Hash, signature []byte) (common.Address, error) {
+	var r, s []byte
+	var v byte
+	switch len(signature) {
+	case 65:
+		r, s, v = signature[:32], signature[32:64], signature[64]
+	case 64:
+		r = signature[:32]
+		s = common.CopyBytes(signature[32:64])
+		v = 27 + s[0]>>7
+		s[0] &= 0x7f
+	default:
+		return common.Address{}, xerrors.Errorf("invalid signature length %d", len(signature))
+	}
+	if v != 27 && v != 28 {
+		return common.Address{}, xerrors.Errorf("invalid signature v value %d", v)
+	}
+	if !crypto.ValidateSignatureValues(v-27, new(big.Int).SetBytes(r), new(big.Int).SetBytes(s), true) {
+		return common.Address{}, xerrors.New("invalid signature r or s value")
+	}
+
+	sig := append(append(common.CopyBytes(r), s...), v-27)
+	pub, err := crypto.SigToPub(accounts.TextHash(requestID.Bytes()), sig)
+	if err != nil {
+		return common.Address{}, xerrors.Errorf("invalid signature: %w", err)
+	}
+	return crypto.PubkeyToAddress(*pub), nil
+}
+
+// CheckWalletSignature checks signature of op the same way as `SimpleWallet._validateSignature`,
+// so that badly signed operations are rejected without simulation.
+// Returns *SignatureError if op is not signed by `owner()` of sender.
+// Nothing is checked if sender is not deployed yet, or has no `owner()`, i.e. it is not a SimpleWallet.
+func (e *EntryPoint) CheckWalletSignature(ctx context.Context, op abi.UserOperation) error {
+	if e.Config.SkipWalletSignatureCheck || len(op.InitCode) > 0 {
+		return nil
+	}
+
+	wallet, err := abi.NewSimpleWallet(op.Sender, e.client)
+	if err != nil {
+		return err
+	}
+	owner, err := wallet.Owner(&bind.CallOpts{Context: ctx})
+	if err != nil {
+		l.Debugf("Skip signature check of wallet %s. Error: %s", op.Sender.Hex(), err.Error())
+		return nil
+	}
+
+	requestID, err := e.RequestID(op)
+	if err != nil {
+		return err
+	}
+	signer, err := recoverSigner(requestID, op.Signature)
+	if err != nil {
+		return &SignatureError{Owner: owner, Reason: err.Error()}
+	}
+	if signer != owner {
+		return &SignatureError{Owner: owner, Signer: signer}
+	}
+	return nil
+}
@@ -0,0 +1,158 @@
+package eth
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/stretchr/testify/require"
+
+	"bundler/abi"
+	"bundler/config"
+)
+
+// fakeWalletNode answers `owner()` of every wallet with owner, or reverts if owner is nil.
+type fakeWalletNode struct {
+	owner *common.Address
+	calls int
+}
+
+func (n *fakeWalletNode) Call(ctx context.Context, args map[string]any, block string) (hexutil.Bytes, error) {
+	n.calls++
+	if n.owner == nil {
+		return nil, errors.New("execution reverted")
+	}
+	return common.LeftPadBytes(n.owner.Bytes(), 32), nil
+}
+
+func signRequestID(t *testing.T, requestID common.Hash) ([]byte, common.Address) {
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	signature, err := crypto.Sign(accounts.TextHash(requestID.Bytes()), key)
+	require.NoError(t, err)
+	signature[64] += 27
+	return signature, crypto.PubkeyToAddress(key.PublicKey)
+}
+
+func Test_recoverSigner(t *testing.T) {
+	requestID := crypto.Keccak256Hash([]byte("request"))
+	signature, signer := signRequestID(t, requestID)
+
+	t.Run("65 bytes", func(t *testing.T) {
+		recovered, err := recoverSigner(requestID, signature)
+		require.NoError(t, err)
+		require.Equal(t, signer, recovered)
+	})
+
+	t.Run("64 bytes", func(t *testing.T) {
+		compact := common.CopyBytes(signature[:64])
+		compact[32] |= (signature[64] - 27) << 7
+		recovered, err := recoverSigner(requestID, compact)
+		require.NoError(t, err)
+		require.Equal(t, signer, recovered)
+	})
+
+	t.Run("v of 0 or 1", func(t *testing.T) {
+		raw := common.CopyBytes(signature)
+		raw[64] -= 27
+		_, err := recoverSigner(requestID, raw)
+		require.ErrorContains(t, err, "v value")
+	})
+
+	t.Run("s in upper half order", func(t *testing.T) {
+		n, _ := new(big.Int).SetString("fffffffffffffffffffffffffffffffebaaedce6af48a03bbfd25e8cd0364141", 16)
+		malleable := common.CopyBytes(signature)
+		s := new(big.Int).Sub(n, new(big.Int).SetBytes(signature[32:64]))
+		copy(malleable[32:64], common.LeftPadBytes(s.Bytes(), 32))
+		malleable[64] ^= 1
+		_, err := recoverSigner(requestID, malleable)
+		require.Error(t, err)
+	})
+
+	t.Run("wrong length", func(t *testing.T) {
+		_, err := recoverSigner(requestID, signature[:2])
+		require.ErrorContains(t, err, "length")
+	})
+}
+
+func Test_CheckWalletSignature(t *testing.T) {
+	before_each := func(t *testing.T, owner *common.Address) (*EntryPoint, *fakeWalletNode) {
+		node := &fakeWalletNode{owner: owner}
+		server := rpc.NewServer()
+		require.NoError(t, server.RegisterName("eth", node))
+		httpServer := httptest.NewServer(server)
+		t.Cleanup(httpServer.Close)
+
+		chain, err := NewChain(&config.ChainConfig{
+			ChainID:                   "80001",
+			RPCServer:                 httpServer.URL,
+			EntrypointContractAddress: "0x8A42F70047a99298822dD1dbA34b454fc49913F2",
+		})
+		require.NoError(t, err)
+		return chain.DefaultEntryPoint(), node
+	}
+	sign := func(t *testing.T, op *abi.UserOperation) common.Address {
+		requestID, err := RequestID(*op, common.HexToAddress("0x8A42F70047a99298822dD1dbA34b454fc49913F2"), big.NewInt(80001))
+		require.NoError(t, err)
+		signature, signer := signRequestID(t, requestID)
+		op.Signature = signature
+		return signer
+	}
+
+	t.Run("signed by owner", func(t *testing.T) {
+		op := emptyOperation()
+		owner := sign(t, &op)
+		entrypoint, _ := before_each(t, &owner)
+
+		require.NoError(t, entrypoint.CheckWalletSignature(context.Background(), op))
+	})
+
+	t.Run("signed by someone else", func(t *testing.T) {
+		owner := common.HexToAddress("0x01")
+		entrypoint, _ := before_each(t, &owner)
+		op := emptyOperation()
+		signer := sign(t, &op)
+
+		err := entrypoint.CheckWalletSignature(context.Background(), op)
+		signatureErr := &SignatureError{}
+		require.ErrorAs(t, err, &signatureErr)
+		require.Equal(t, owner, signatureErr.Owner)
+		require.Equal(t, signer, signatureErr.Signer)
+		require.ErrorContains(t, err, "signature does not match wallet owner")
+	})
+
+	t.Run("malformed signature", func(t *testing.T) {
+		owner := common.HexToAddress("0x01")
+		entrypoint, _ := before_each(t, &owner)
+		op := emptyOperation()
+		op.Signature = []byte{0xe0, 0xdd}
+
+		err := entrypoint.CheckWalletSignature(context.Background(), op)
+		require.ErrorContains(t, err, "signature does not match wallet owner")
+	})
+
+	t.Run("not a SimpleWallet", func(t *testing.T) {
+		entrypoint, node := before_each(t, nil)
+		op := emptyOperation()
+
+		require.NoError(t, entrypoint.CheckWalletSignature(context.Background(), op))
+		require.Equal(t, 1, node.calls)
+	})
+
+	t.Run("not deployed yet", func(t *testing.T) {
+		owner := common.HexToAddress("0x01")
+		entrypoint, node := before_each(t, &owner)
+		op := emptyOperation()
+		op.InitCode = []byte{0x01}
+
+		require.NoError(t, entrypoint.CheckWalletSignature(context.Background(), op))
+		require.Zero(t, node.calls)
+	})
+}
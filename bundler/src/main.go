@@ -4,39 +4,19 @@ import (
 	"bundler/config"
 	"bundler/controller"
 	"bundler/eth"
-	"fmt"
 
-	"github.com/aws/aws-lambda-go/events"
 	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/sirupsen/logrus"
 )
 
-func handler(request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
-	path, ok := request.PathParameters["proxy"]
-	if !ok {
-		return events.APIGatewayProxyResponse{
-			StatusCode: 400,
-			Body:       "Bad Request",
-		}, nil
-	}
-
-	switch path {
-	case "healthz":
-		return controller.Healthz(request)
-	case "handle":
-		return controller.HandleOps(request)
-	default:
-		return events.APIGatewayProxyResponse{
-			Body:       fmt.Sprintf("Not Found for request %v.", request),
-			StatusCode: 404,
-		}, nil
-	}
-}
-
 func init() {
 	config.InitFromAWSSecret()
 	eth.Init()
+	if config.C.Mempool != nil {
+		logrus.Warnf("Mempool is not supported in Lambda, operations will be sent immediately.")
+	}
 }
 
 func main() {
-	lambda.Start(handler)
+	lambda.Start(controller.NewRouter().LambdaHandler)
 }
@@ -0,0 +1,220 @@
+package mempool
+
+import (
+	"encoding/json"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/syndtr/goleveldb/leveldb"
+	"github.com/syndtr/goleveldb/leveldb/util"
+	"golang.org/x/xerrors"
+)
+
+// Key layout:
+//
+//	r/<request ID>                -> Record JSON
+//	s/<sender><nonce as uint256>  -> request ID
+//	p/<request ID>                -> empty, exists only while pending
+//	b/<bundle ID>                 -> BundleRecord JSON
+//	q/<bundle ID>                 -> empty, exists only while submitted
+var (
+	prefixRecord      = []byte("r/")
+	prefixSenderNonce = []byte("s/")
+	prefixPending     = []byte("p/")
+	prefixBundle      = []byte("b/")
+	prefixSubmitted   = []byte("q/")
+)
+
+type levelDBStore struct {
+	db *leveldb.DB
+}
+
+// NewLevelDBStore opens (or creates) an on-disk store in dir.
+func NewLevelDBStore(dir string) (Store, error) {
+	db, err := leveldb.OpenFile(dir, nil)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to open mempool database %s: %w", dir, err)
+	}
+	return &levelDBStore{db: db}, nil
+}
+
+func recordKey(requestID common.Hash) []byte {
+	return append(append([]byte{}, prefixRecord...), requestID.Bytes()...)
+}
+
+func pendingKey(requestID common.Hash) []byte {
+	return append(append([]byte{}, prefixPending...), requestID.Bytes()...)
+}
+
+func bundleKey(id common.Hash) []byte {
+	return append(append([]byte{}, prefixBundle...), id.Bytes()...)
+}
+
+func submittedKey(id common.Hash) []byte {
+	return append(append([]byte{}, prefixSubmitted...), id.Bytes()...)
+}
+
+func senderNonceKey(sender common.Address, nonce string) ([]byte, error) {
+	n, ok := big.NewInt(0).SetString(nonce, 10)
+	if !ok || n.Sign() < 0 || n.BitLen() > 256 {
+		return nil, xerrors.Errorf("invalid nonce: %s", nonce)
+	}
+	key := append(append([]byte{}, prefixSenderNonce...), sender.Bytes()...)
+	return append(key, common.LeftPadBytes(n.Bytes(), 32)...), nil
+}
+
+func (s *levelDBStore) Put(record Record) error {
+	value, err := json.Marshal(record)
+	if err != nil {
+		return xerrors.Errorf("failed to encode record: %w", err)
+	}
+	snKey, err := senderNonceKey(record.Op.Sender, record.Op.Nonce.String())
+	if err != nil {
+		return err
+	}
+
+	batch := new(leveldb.Batch)
+	batch.Put(recordKey(record.RequestID), value)
+	batch.Put(snKey, record.RequestID.Bytes())
+	if record.Status == StatusPending {
+		batch.Put(pendingKey(record.RequestID), []byte{})
+	} else {
+		batch.Delete(pendingKey(record.RequestID))
+	}
+	return s.db.Write(batch, nil)
+}
+
+func (s *levelDBStore) Get(requestID common.Hash) (*Record, error) {
+	value, err := s.db.Get(recordKey(requestID), nil)
+	if err == leveldb.ErrNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	record := Record{}
+	if err := json.Unmarshal(value, &record); err != nil {
+		return nil, xerrors.Errorf("failed to decode record %s: %w", requestID.Hex(), err)
+	}
+	return &record, nil
+}
+
+func (s *levelDBStore) GetBySenderNonce(sender common.Address, nonce string) (*Record, error) {
+	snKey, err := senderNonceKey(sender, nonce)
+	if err != nil {
+		return nil, err
+	}
+	value, err := s.db.Get(snKey, nil)
+	if err == leveldb.ErrNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return s.Get(common.BytesToHash(value))
+}
+
+func (s *levelDBStore) Pending() ([]Record, error) {
+	iter := s.db.NewIterator(util.BytesPrefix(prefixPending), nil)
+	defer iter.Release()
+
+	result := make([]Record, 0)
+	for iter.Next() {
+		requestID := common.BytesToHash(iter.Key()[len(prefixPending):])
+		record, err := s.Get(requestID)
+		if err != nil {
+			return nil, err
+		}
+		if record != nil {
+			result = append(result, *record)
+		}
+	}
+	return result, iter.Error()
+}
+
+func (s *levelDBStore) PutBundle(bundle BundleRecord) error {
+	// `types.Receipt` cannot be decoded without logs.
+	if bundle.Receipt != nil && bundle.Receipt.Logs == nil {
+		bundle.Receipt.Logs = []*types.Log{}
+	}
+	value, err := json.Marshal(bundle)
+	if err != nil {
+		return xerrors.Errorf("failed to encode bundle: %w", err)
+	}
+
+	batch := new(leveldb.Batch)
+	batch.Put(bundleKey(bundle.ID), value)
+	if bundle.Status == StatusSubmitted {
+		batch.Put(submittedKey(bundle.ID), []byte{})
+	} else {
+		batch.Delete(submittedKey(bundle.ID))
+	}
+	return s.db.Write(batch, nil)
+}
+
+func (s *levelDBStore) GetBundle(id common.Hash) (*BundleRecord, error) {
+	value, err := s.db.Get(bundleKey(id), nil)
+	if err == leveldb.ErrNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	bundle := BundleRecord{}
+	if err := json.Unmarshal(value, &bundle); err != nil {
+		return nil, xerrors.Errorf("failed to decode bundle %s: %w", id.Hex(), err)
+	}
+	return &bundle, nil
+}
+
+func (s *levelDBStore) SubmittedBundles() ([]BundleRecord, error) {
+	iter := s.db.NewIterator(util.BytesPrefix(prefixSubmitted), nil)
+	defer iter.Release()
+
+	result := make([]BundleRecord, 0)
+	for iter.Next() {
+		id := common.BytesToHash(iter.Key()[len(prefixSubmitted):])
+		bundle, err := s.GetBundle(id)
+		if err != nil {
+			return nil, err
+		}
+		if bundle != nil {
+			result = append(result, *bundle)
+		}
+	}
+	return result, iter.Error()
+}
+
+func (s *levelDBStore) Delete(requestID common.Hash) error {
+	record, err := s.Get(requestID)
+	if err != nil || record == nil {
+		return err
+	}
+	snKey, err := senderNonceKey(record.Op.Sender, record.Op.Nonce.String())
+	if err != nil {
+		return err
+	}
+
+	batch := new(leveldb.Batch)
+	batch.Delete(recordKey(requestID))
+	batch.Delete(pendingKey(requestID))
+	// The same sender and nonce may be taken by a newer record.
+	if value, err := s.db.Get(snKey, nil); err == nil && common.BytesToHash(value) == requestID {
+		batch.Delete(snKey)
+	}
+	return s.db.Write(batch, nil)
+}
+
+func (s *levelDBStore) DeleteBundle(id common.Hash) error {
+	batch := new(leveldb.Batch)
+	batch.Delete(bundleKey(id))
+	batch.Delete(submittedKey(id))
+	return s.db.Write(batch, nil)
+}
+
+func (s *levelDBStore) Close() error {
+	return s.db.Close()
+}
@@ -0,0 +1,327 @@
+package mempool
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/xerrors"
+
+	"bundler/abi"
+)
+
+var (
+	l = logrus.WithField("module", "mempool")
+
+	ErrDuplicated = xerrors.New("operation with same sender and nonce is already pending")
+)
+
+// SubmitFunc sends a bundle on chain, e.g. `eth.SendHandleOps`.
+type SubmitFunc func(ctx context.Context, ops []abi.UserOperation) (*types.Transaction, error)
+
+// SimulateFunc validates a single operation against current chain state, e.g. `eth.Simulate`.
+type SimulateFunc func(ctx context.Context, op abi.UserOperation) error
+
+type Entry struct {
+	RequestID common.Hash
+	Op        abi.UserOperation
+	AddedAt   time.Time
+}
+
+type senderNonce struct {
+	sender common.Address
+	nonce  string
+}
+
+func keyOf(op abi.UserOperation) senderNonce {
+	return senderNonce{sender: op.Sender, nonce: op.Nonce.String()}
+}
+
+// Mempool keeps simulated operations until they are bundled.
+// Pending operations are cached in memory, while every status change is written to store.
+type Mempool struct {
+	mu            sync.Mutex
+	store         Store
+	entries       map[common.Hash]*Entry
+	bySenderNonce map[senderNonce]common.Hash
+
+	interval      time.Duration
+	maxBundleSize int
+	submit        SubmitFunc
+	simulate      SimulateFunc
+	// Wakes up bundling loop before next tick.
+	trigger chan struct{}
+}
+
+func New(store Store, interval time.Duration, maxBundleSize int, submit SubmitFunc, simulate SimulateFunc) *Mempool {
+	return &Mempool{
+		store:         store,
+		entries:       make(map[common.Hash]*Entry),
+		bySenderNonce: make(map[senderNonce]common.Hash),
+		interval:      interval,
+		maxBundleSize: maxBundleSize,
+		submit:        submit,
+		simulate:      simulate,
+		trigger:       make(chan struct{}, 1),
+	}
+}
+
+// Add puts an already simulated operation into pool.
+func (m *Mempool) Add(requestID common.Hash, op abi.UserOperation) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if err := m.checkAdd(requestID, op); err != nil {
+		return err
+	}
+	return m.put([]common.Hash{requestID}, []abi.UserOperation{op})
+}
+
+// AddAll puts already simulated operations into pool, either all of them or none.
+// The error names the first operation that cannot be added.
+func (m *Mempool) AddAll(requestIDs []common.Hash, ops []abi.UserOperation) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	seen := make(map[senderNonce]bool, len(ops))
+	for i, op := range ops {
+		if err := m.checkAdd(requestIDs[i], op); err != nil {
+			return xerrors.Errorf("user operation #%d: %w", i, err)
+		}
+		if seen[keyOf(op)] {
+			return xerrors.Errorf("user operation #%d: %w", i, ErrDuplicated)
+		}
+		seen[keyOf(op)] = true
+	}
+	return m.put(requestIDs, ops)
+}
+
+// put saves operations checked by checkAdd as pending, requires m.mu to be held.
+func (m *Mempool) put(requestIDs []common.Hash, ops []abi.UserOperation) error {
+	now := time.Now()
+	for i, op := range ops {
+		if err := m.store.Put(Record{
+			RequestID: requestIDs[i],
+			Op:        op,
+			Status:    StatusPending,
+			AddedAt:   now,
+			UpdatedAt: now,
+		}); err != nil {
+			return xerrors.Errorf("failed to save operation: %w", err)
+		}
+		m.addEntry(&Entry{
+			RequestID: requestIDs[i],
+			Op:        op,
+			AddedAt:   now,
+		})
+		l.Infof("Operation %s added, %d pending", requestIDs[i].Hex(), len(m.entries))
+	}
+
+	if len(m.entries) >= m.maxBundleSize {
+		select {
+		case m.trigger <- struct{}{}:
+		default:
+		}
+	}
+	return nil
+}
+
+// checkAdd tells if op can enter pool, requires m.mu to be held.
+func (m *Mempool) checkAdd(requestID common.Hash, op abi.UserOperation) error {
+	if _, ok := m.entries[requestID]; ok {
+		return ErrDuplicated
+	}
+	if _, ok := m.bySenderNonce[keyOf(op)]; ok {
+		return ErrDuplicated
+	}
+	// Operations already sent cannot be replaced, unless they never made it on chain.
+	existing, err := m.store.GetBySenderNonce(op.Sender, op.Nonce.String())
+	if err != nil {
+		return xerrors.Errorf("failed to query mempool store: %w", err)
+	}
+	if existing != nil && existing.Status != StatusDropped && existing.Status != StatusReverted {
+		return ErrDuplicated
+	}
+	return nil
+}
+
+// addEntry requires m.mu to be held.
+func (m *Mempool) addEntry(entry *Entry) {
+	m.entries[entry.RequestID] = entry
+	m.bySenderNonce[keyOf(entry.Op)] = entry.RequestID
+}
+
+// Get returns nil if the operation has never entered mempool.
+func (m *Mempool) Get(requestID common.Hash) (*Record, error) {
+	return m.store.Get(requestID)
+}
+
+// Restore loads pending operations from store after restart.
+// Operations are re-simulated against current chain state,
+// those failing are dropped with reason recorded.
+func (m *Mempool) Restore(ctx context.Context) error {
+	records, err := m.store.Pending()
+	if err != nil {
+		return xerrors.Errorf("failed to load pending operations: %w", err)
+	}
+
+	kept := 0
+	for _, record := range records {
+		if err := m.simulate(ctx, record.Op); err != nil {
+			l.Warnf("Operation %s dropped on restore: %s", record.RequestID.Hex(), err.Error())
+			m.setStatus(record.RequestID, StatusDropped, "simulation failed on restore: "+err.Error(), common.Hash{})
+			continue
+		}
+
+		m.mu.Lock()
+		m.addEntry(&Entry{
+			RequestID: record.RequestID,
+			Op:        record.Op,
+			AddedAt:   record.AddedAt,
+		})
+		m.mu.Unlock()
+		kept++
+	}
+	l.Infof("Restored %d pending operations, %d dropped", kept, len(records)-kept)
+	return nil
+}
+
+// setStatus updates status of an operation in store.
+func (m *Mempool) setStatus(requestID common.Hash, status Status, reason string, txHash common.Hash) {
+	setStatus(m.store, requestID, status, reason, txHash)
+}
+
+func setStatus(store Store, requestID common.Hash, status Status, reason string, txHash common.Hash) {
+	record, err := store.Get(requestID)
+	if err != nil || record == nil {
+		l.Errorf("Failed to load operation %s: %v", requestID.Hex(), err)
+		return
+	}
+	record.Status = status
+	record.Reason = reason
+	record.TxHash = txHash
+	record.UpdatedAt = time.Now()
+	if err := store.Put(*record); err != nil {
+		l.Errorf("Failed to update operation %s: %s", requestID.Hex(), err.Error())
+	}
+}
+
+func (m *Mempool) Len() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.entries)
+}
+
+func (m *Mempool) remove(requestIDs ...common.Hash) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, requestID := range requestIDs {
+		entry, ok := m.entries[requestID]
+		if !ok {
+			continue
+		}
+		delete(m.bySenderNonce, keyOf(entry.Op))
+		delete(m.entries, requestID)
+	}
+}
+
+// nextBundle picks at most `maxBundleSize` operations, highest priority fee first.
+// Only the lowest-nonce operation of each sender is included in a bundle.
+func (m *Mempool) nextBundle() []*Entry {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	lowest := make(map[common.Address]*Entry)
+	for _, entry := range m.entries {
+		current, ok := lowest[entry.Op.Sender]
+		if !ok || entry.Op.Nonce.Cmp(current.Op.Nonce) < 0 {
+			lowest[entry.Op.Sender] = entry
+		}
+	}
+
+	candidates := make([]*Entry, 0, len(lowest))
+	for _, entry := range lowest {
+		candidates = append(candidates, entry)
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		cmp := candidates[i].Op.MaxPriorityFeePerGas.Cmp(candidates[j].Op.MaxPriorityFeePerGas)
+		if cmp != 0 {
+			return cmp > 0
+		}
+		return candidates[i].AddedAt.Before(candidates[j].AddedAt)
+	})
+
+	if len(candidates) > m.maxBundleSize {
+		candidates = candidates[:m.maxBundleSize]
+	}
+	return candidates
+}
+
+// Bundle submits one bundle from pending operations, and returns how many operations are sent.
+// If submission fails, operations that no longer pass simulation are dropped,
+// the rest are kept for next bundle.
+func (m *Mempool) Bundle(ctx context.Context) int {
+	bundle := m.nextBundle()
+	if len(bundle) == 0 {
+		return 0
+	}
+
+	ops := make([]abi.UserOperation, 0, len(bundle))
+	requestIDs := make([]common.Hash, 0, len(bundle))
+	for _, entry := range bundle {
+		ops = append(ops, entry.Op)
+		requestIDs = append(requestIDs, entry.RequestID)
+	}
+
+	tx, err := m.submit(ctx, ops)
+	if err == nil {
+		l.Infof("Bundle of %d operations sent in tx %s", len(ops), tx.Hash().Hex())
+		m.remove(requestIDs...)
+		for _, requestID := range requestIDs {
+			m.setStatus(requestID, StatusSubmitted, "", tx.Hash())
+		}
+		if err := m.store.PutBundle(newBundleRecord(tx, requestIDs)); err != nil {
+			l.Errorf("Failed to save bundle %s: %s", tx.Hash().Hex(), err.Error())
+		}
+		return len(ops)
+	}
+
+	l.Warnf("Failed to send bundle of %d operations: %s", len(ops), err.Error())
+	for _, entry := range bundle {
+		if err := m.simulate(ctx, entry.Op); err != nil {
+			l.Warnf("Operation %s dropped: %s", entry.RequestID.Hex(), err.Error())
+			m.remove(entry.RequestID)
+			m.setStatus(entry.RequestID, StatusDropped, "simulation failed after bundle failure: "+err.Error(), common.Hash{})
+		}
+	}
+	return 0
+}
+
+// Run sends bundles every interval, or as soon as `maxBundleSize` operations are pending.
+// Blocks until ctx is done.
+func (m *Mempool) Run(ctx context.Context) {
+	ticker := time.NewTicker(m.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-m.trigger:
+		}
+		// Keep sending while full bundles are pending, unless a bundle fails.
+		for m.Bundle(ctx) > 0 && m.Len() >= m.maxBundleSize {
+			select {
+			case <-ctx.Done():
+				return
+			default:
+			}
+		}
+	}
+}
@@ -0,0 +1,234 @@
+package mempool
+
+import (
+	"bundler/abi"
+	"context"
+	"math/big"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/xerrors"
+)
+
+var bundlerKey, _ = crypto.HexToECDSA("0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef")
+
+type fakeChain struct {
+	mu      sync.Mutex
+	bundles [][]abi.UserOperation
+	txs     []*types.Transaction
+	// Submission fails if any op in bundle is from this sender.
+	badSender common.Address
+}
+
+func signTx(nonce uint64, tipCap int64) *types.Transaction {
+	return types.MustSignNewTx(bundlerKey, types.LatestSignerForChainID(big.NewInt(1337)), &types.DynamicFeeTx{
+		ChainID:   big.NewInt(1337),
+		Nonce:     nonce,
+		GasTipCap: big.NewInt(tipCap),
+		GasFeeCap: big.NewInt(100),
+		Gas:       100000,
+	})
+}
+
+func (c *fakeChain) submit(ctx context.Context, ops []abi.UserOperation) (*types.Transaction, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, op := range ops {
+		if op.Sender == c.badSender {
+			return nil, xerrors.New("FailedOp")
+		}
+	}
+	c.bundles = append(c.bundles, ops)
+	tx := signTx(uint64(len(c.txs)), 1)
+	c.txs = append(c.txs, tx)
+	return tx, nil
+}
+
+func (c *fakeChain) simulate(ctx context.Context, op abi.UserOperation) error {
+	if op.Sender == c.badSender {
+		return xerrors.New("FailedOp")
+	}
+	return nil
+}
+
+func (c *fakeChain) bundleCount() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.bundles)
+}
+
+func newOp(sender common.Address, nonce, tip int64) (common.Hash, abi.UserOperation) {
+	op := abi.UserOperation{
+		Sender:               sender,
+		Nonce:                big.NewInt(nonce),
+		MaxPriorityFeePerGas: big.NewInt(tip),
+	}
+	requestID := crypto.Keccak256Hash(sender.Bytes(), op.Nonce.Bytes())
+	return requestID, op
+}
+
+func Test_Add(t *testing.T) {
+	t.Run("duplicated sender and nonce", func(t *testing.T) {
+		chain := &fakeChain{}
+		pool := New(NewMemoryStore(), time.Hour, 10, chain.submit, chain.simulate)
+
+		requestID, op := newOp(common.HexToAddress("0x01"), 0, 1)
+		require.NoError(t, pool.Add(requestID, op))
+		require.ErrorIs(t, pool.Add(common.HexToHash("0x02"), op), ErrDuplicated)
+		require.Equal(t, 1, pool.Len())
+	})
+
+	t.Run("all or none", func(t *testing.T) {
+		chain := &fakeChain{}
+		store := NewMemoryStore()
+		pool := New(store, time.Hour, 10, chain.submit, chain.simulate)
+
+		pendingID, pendingOp := newOp(common.HexToAddress("0x01"), 0, 1)
+		require.NoError(t, pool.Add(pendingID, pendingOp))
+
+		addedID, addedOp := newOp(common.HexToAddress("0x02"), 0, 1)
+		err := pool.AddAll([]common.Hash{addedID, common.HexToHash("0x03")}, []abi.UserOperation{addedOp, pendingOp})
+		require.ErrorIs(t, err, ErrDuplicated)
+		require.Contains(t, err.Error(), "user operation #1")
+		require.Equal(t, 1, pool.Len())
+		record, err := store.Get(addedID)
+		require.NoError(t, err)
+		require.Nil(t, record)
+
+		// Same sender and nonce twice in one batch.
+		err = pool.AddAll([]common.Hash{addedID, common.HexToHash("0x03")}, []abi.UserOperation{addedOp, addedOp})
+		require.ErrorIs(t, err, ErrDuplicated)
+		require.Equal(t, 1, pool.Len())
+
+		require.NoError(t, pool.AddAll([]common.Hash{addedID}, []abi.UserOperation{addedOp}))
+		require.Equal(t, 2, pool.Len())
+	})
+}
+
+func Test_Bundle(t *testing.T) {
+	t.Run("priority fee and sender", func(t *testing.T) {
+		chain := &fakeChain{}
+		pool := New(NewMemoryStore(), time.Hour, 2, chain.submit, chain.simulate)
+
+		alice, bob, carol := common.HexToAddress("0x0a"), common.HexToAddress("0x0b"), common.HexToAddress("0x0c")
+		require.NoError(t, pool.Add(newOp(alice, 1, 5)))
+		require.NoError(t, pool.Add(newOp(alice, 0, 3)))
+		require.NoError(t, pool.Add(newOp(bob, 0, 1)))
+		require.NoError(t, pool.Add(newOp(carol, 0, 2)))
+
+		require.Equal(t, 2, pool.Bundle(context.Background()))
+		require.Len(t, chain.bundles, 1)
+		require.Equal(t, alice, chain.bundles[0][0].Sender)
+		require.EqualValues(t, 0, chain.bundles[0][0].Nonce.Int64())
+		require.Equal(t, carol, chain.bundles[0][1].Sender)
+		require.Equal(t, 2, pool.Len())
+	})
+
+	t.Run("drop invalid operations", func(t *testing.T) {
+		chain := &fakeChain{badSender: common.HexToAddress("0x0b")}
+		pool := New(NewMemoryStore(), time.Hour, 10, chain.submit, chain.simulate)
+
+		require.NoError(t, pool.Add(newOp(common.HexToAddress("0x0a"), 0, 1)))
+		require.NoError(t, pool.Add(newOp(common.HexToAddress("0x0b"), 0, 1)))
+
+		require.Equal(t, 0, pool.Bundle(context.Background()))
+		require.Equal(t, 1, pool.Len())
+		require.Equal(t, 1, pool.Bundle(context.Background()))
+		require.Equal(t, 0, pool.Len())
+	})
+}
+
+func Test_Run(t *testing.T) {
+	t.Run("max bundle size", func(t *testing.T) {
+		chain := &fakeChain{}
+		pool := New(NewMemoryStore(), time.Hour, 2, chain.submit, chain.simulate)
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		go pool.Run(ctx)
+
+		require.NoError(t, pool.Add(newOp(common.HexToAddress("0x0a"), 0, 1)))
+		require.NoError(t, pool.Add(newOp(common.HexToAddress("0x0b"), 0, 1)))
+		require.Eventually(t, func() bool { return chain.bundleCount() == 1 }, time.Second, 10*time.Millisecond)
+	})
+
+	t.Run("interval", func(t *testing.T) {
+		chain := &fakeChain{}
+		pool := New(NewMemoryStore(), 50*time.Millisecond, 10, chain.submit, chain.simulate)
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		go pool.Run(ctx)
+
+		require.NoError(t, pool.Add(newOp(common.HexToAddress("0x0a"), 0, 1)))
+		require.Eventually(t, func() bool { return chain.bundleCount() == 1 }, time.Second, 10*time.Millisecond)
+	})
+
+	t.Run("stops while full bundles are pending", func(t *testing.T) {
+		chain := &fakeChain{}
+		pool := New(NewMemoryStore(), time.Hour, 1, chain.submit, chain.simulate)
+		// One sender per bundle, so that the pool stays full after each bundle.
+		for nonce := int64(0); nonce < 100; nonce++ {
+			require.NoError(t, pool.Add(newOp(common.HexToAddress("0x0a"), nonce, 1)))
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		done := make(chan struct{})
+		go func() {
+			pool.Run(ctx)
+			close(done)
+		}()
+		require.Eventually(t, func() bool {
+			select {
+			case <-done:
+				return true
+			default:
+				return false
+			}
+		}, time.Second, 10*time.Millisecond)
+		require.LessOrEqual(t, chain.bundleCount(), 1)
+	})
+}
+
+func Test_Restore(t *testing.T) {
+	t.Run("leveldb", func(t *testing.T) {
+		dir := t.TempDir()
+		chain := &fakeChain{badSender: common.HexToAddress("0x0b")}
+
+		store, err := NewLevelDBStore(dir)
+		require.NoError(t, err)
+		pool := New(store, time.Hour, 10, chain.submit, chain.simulate)
+		goodID, goodOp := newOp(common.HexToAddress("0x0a"), 0, 1)
+		badID, badOp := newOp(common.HexToAddress("0x0b"), 0, 1)
+		require.NoError(t, pool.Add(goodID, goodOp))
+		require.NoError(t, pool.Add(badID, badOp))
+		require.NoError(t, store.Close())
+
+		// Restart
+		store, err = NewLevelDBStore(dir)
+		require.NoError(t, err)
+		defer store.Close()
+		pool = New(store, time.Hour, 10, chain.submit, chain.simulate)
+		require.NoError(t, pool.Restore(context.Background()))
+		require.Equal(t, 1, pool.Len())
+
+		record, err := pool.Get(badID)
+		require.NoError(t, err)
+		require.Equal(t, StatusDropped, record.Status)
+		require.Contains(t, record.Reason, "FailedOp")
+
+		require.Equal(t, 1, pool.Bundle(context.Background()))
+		record, err = pool.Get(goodID)
+		require.NoError(t, err)
+		require.Equal(t, StatusSubmitted, record.Status)
+		require.Equal(t, chain.txs[0].Hash(), record.TxHash)
+		require.EqualValues(t, 0, record.Op.Nonce.Int64())
+
+		// Nonce already used
+		require.ErrorIs(t, pool.Add(common.HexToHash("0x03"), goodOp), ErrDuplicated)
+	})
+}
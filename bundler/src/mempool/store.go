@@ -0,0 +1,206 @@
+package mempool
+
+import (
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+
+	"bundler/abi"
+)
+
+type Status string
+
+const (
+	// Waiting to be bundled.
+	StatusPending Status = "pending"
+	// Sent on chain in a bundle, waiting to be mined.
+	StatusSubmitted Status = "submitted"
+	// Bundle transaction is mined successfully.
+	// The operation itself may still fail, see `UserOperationEvent`.
+	StatusIncluded Status = "included"
+	// Bundle transaction is mined but reverted.
+	StatusReverted Status = "reverted"
+	// Removed from mempool without being mined, see `Reason`.
+	StatusDropped Status = "dropped"
+)
+
+type Record struct {
+	RequestID common.Hash       `json:"request_id"`
+	Op        abi.UserOperation `json:"op"`
+	Status    Status            `json:"status"`
+	// Why the operation is dropped or reverted.
+	Reason string `json:"reason,omitempty"`
+	// Latest bundle transaction, if submitted.
+	TxHash    common.Hash `json:"tx_hash,omitempty"`
+	AddedAt   time.Time   `json:"added_at"`
+	UpdatedAt time.Time   `json:"updated_at"`
+}
+
+// BundleRecord tracks a bundle transaction until it is mined.
+type BundleRecord struct {
+	// Hash of the first transaction sent, kept when the bundle is re-sent.
+	ID         common.Hash    `json:"id"`
+	From       common.Address `json:"from"`
+	Nonce      uint64         `json:"nonce"`
+	RequestIDs []common.Hash  `json:"request_ids"`
+	// Every transaction sent for this bundle, the last one pays the highest fees.
+	Txs []*types.Transaction `json:"txs"`
+	// One of `StatusSubmitted`, `StatusIncluded`, `StatusReverted` or `StatusDropped`.
+	Status Status `json:"status"`
+	Reason string `json:"reason,omitempty"`
+	// Latest block number when the last transaction was seen pending, 0 if not checked yet.
+	SentBlock uint64 `json:"sent_block"`
+	Resends   int    `json:"resends"`
+	// Following fields are set once a transaction of the bundle is mined.
+	TxHash            common.Hash    `json:"tx_hash,omitempty"`
+	Receipt           *types.Receipt `json:"receipt,omitempty"`
+	GasUsed           uint64         `json:"gas_used,omitempty"`
+	EffectiveGasPrice *big.Int       `json:"effective_gas_price,omitempty"`
+	CreatedAt         time.Time      `json:"created_at"`
+	UpdatedAt         time.Time      `json:"updated_at"`
+}
+
+// Store keeps records of all operations that have entered mempool.
+type Store interface {
+	// Put creates or overwrites record with same request ID.
+	Put(record Record) error
+	// Get returns nil if record is not found.
+	Get(requestID common.Hash) (*Record, error)
+	// GetBySenderNonce returns nil if record is not found.
+	GetBySenderNonce(sender common.Address, nonce string) (*Record, error)
+	// Pending lists all records in `StatusPending`.
+	Pending() ([]Record, error)
+	// PutBundle creates or overwrites bundle with same ID.
+	PutBundle(bundle BundleRecord) error
+	// GetBundle returns nil if bundle is not found.
+	GetBundle(id common.Hash) (*BundleRecord, error)
+	// SubmittedBundles lists all bundles in `StatusSubmitted`.
+	SubmittedBundles() ([]BundleRecord, error)
+	// Delete removes record with request ID, if any.
+	Delete(requestID common.Hash) error
+	// DeleteBundle removes bundle with ID, if any. Records of its operations are kept.
+	DeleteBundle(id common.Hash) error
+	Close() error
+}
+
+type memoryStore struct {
+	mu            sync.Mutex
+	records       map[common.Hash]Record
+	bySenderNonce map[senderNonce]common.Hash
+	bundles       map[common.Hash]BundleRecord
+}
+
+// NewMemoryStore creates a store which is lost when the process exits.
+func NewMemoryStore() Store {
+	return &memoryStore{
+		records:       make(map[common.Hash]Record),
+		bySenderNonce: make(map[senderNonce]common.Hash),
+		bundles:       make(map[common.Hash]BundleRecord),
+	}
+}
+
+func (s *memoryStore) Put(record Record) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.records[record.RequestID] = record
+	s.bySenderNonce[keyOf(record.Op)] = record.RequestID
+	return nil
+}
+
+func (s *memoryStore) Get(requestID common.Hash) (*Record, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	record, ok := s.records[requestID]
+	if !ok {
+		return nil, nil
+	}
+	return &record, nil
+}
+
+func (s *memoryStore) GetBySenderNonce(sender common.Address, nonce string) (*Record, error) {
+	s.mu.Lock()
+	requestID, ok := s.bySenderNonce[senderNonce{sender: sender, nonce: nonce}]
+	s.mu.Unlock()
+	if !ok {
+		return nil, nil
+	}
+	return s.Get(requestID)
+}
+
+func (s *memoryStore) Pending() ([]Record, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	result := make([]Record, 0)
+	for _, record := range s.records {
+		if record.Status == StatusPending {
+			result = append(result, record)
+		}
+	}
+	return result, nil
+}
+
+func (s *memoryStore) PutBundle(bundle BundleRecord) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.bundles[bundle.ID] = bundle
+	return nil
+}
+
+func (s *memoryStore) GetBundle(id common.Hash) (*BundleRecord, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	bundle, ok := s.bundles[id]
+	if !ok {
+		return nil, nil
+	}
+	return &bundle, nil
+}
+
+func (s *memoryStore) SubmittedBundles() ([]BundleRecord, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	result := make([]BundleRecord, 0)
+	for _, bundle := range s.bundles {
+		if bundle.Status == StatusSubmitted {
+			result = append(result, bundle)
+		}
+	}
+	return result, nil
+}
+
+func (s *memoryStore) Delete(requestID common.Hash) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	record, ok := s.records[requestID]
+	if !ok {
+		return nil
+	}
+	delete(s.records, requestID)
+	// The same sender and nonce may be taken by a newer record.
+	if key := keyOf(record.Op); s.bySenderNonce[key] == requestID {
+		delete(s.bySenderNonce, key)
+	}
+	return nil
+}
+
+func (s *memoryStore) DeleteBundle(id common.Hash) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.bundles, id)
+	return nil
+}
+
+func (s *memoryStore) Close() error {
+	return nil
+}
@@ -0,0 +1,216 @@
+package mempool
+
+import (
+	"context"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"golang.org/x/xerrors"
+
+	"bundler/abi"
+)
+
+// TxBackend is the chain access needed by Tracker.
+type TxBackend interface {
+	BlockNumber(ctx context.Context) (uint64, error)
+	// NonceAt returns nonce of account at latest block.
+	NonceAt(ctx context.Context, account common.Address) (uint64, error)
+	// Receipt returns receipt and effective gas price of tx, or nil receipt if tx is not mined yet.
+	Receipt(ctx context.Context, tx *types.Transaction) (*types.Receipt, *big.Int, error)
+	// Resend replaces tx by one with same nonce and bumped fees.
+	Resend(ctx context.Context, tx *types.Transaction) (*types.Transaction, error)
+	// ResyncNonce is called when a transaction of account is dropped.
+	ResyncNonce(ctx context.Context, account common.Address) error
+}
+
+func newBundleRecord(tx *types.Transaction, requestIDs []common.Hash) BundleRecord {
+	from, err := types.Sender(types.LatestSignerForChainID(tx.ChainId()), tx)
+	if err != nil {
+		l.Errorf("Failed to recover sender of tx %s: %s", tx.Hash().Hex(), err.Error())
+	}
+	now := time.Now()
+	return BundleRecord{
+		ID:         tx.Hash(),
+		From:       from,
+		Nonce:      tx.Nonce(),
+		RequestIDs: requestIDs,
+		Txs:        []*types.Transaction{tx},
+		Status:     StatusSubmitted,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+}
+
+// Tracker watches submitted bundles until they are mined.
+// A bundle still pending after `resendAfterBlocks` blocks is re-sent with bumped fees,
+// at most `maxResends` times.
+// If `evictFinished` is set, a bundle and records of its operations are deleted from store once it is mined or dropped,
+// e.g. when nothing else reads the store.
+type Tracker struct {
+	store             Store
+	backend           TxBackend
+	resendAfterBlocks uint64
+	maxResends        int
+	evictFinished     bool
+}
+
+func NewTracker(store Store, backend TxBackend, resendAfterBlocks uint64, maxResends int, evictFinished bool) *Tracker {
+	return &Tracker{
+		store:             store,
+		backend:           backend,
+		resendAfterBlocks: resendAfterBlocks,
+		maxResends:        maxResends,
+		evictFinished:     evictFinished,
+	}
+}
+
+// Track records a bundle of ops sent without mempool, e.g. directly by `/handle`,
+// so that it is watched the same as bundles sent by mempool.
+func (t *Tracker) Track(tx *types.Transaction, requestIDs []common.Hash, ops []abi.UserOperation) error {
+	now := time.Now()
+	for i, op := range ops {
+		record := Record{
+			RequestID: requestIDs[i],
+			Op:        op,
+			Status:    StatusSubmitted,
+			TxHash:    tx.Hash(),
+			AddedAt:   now,
+			UpdatedAt: now,
+		}
+		if err := t.store.Put(record); err != nil {
+			return xerrors.Errorf("failed to save operation %s: %w", requestIDs[i].Hex(), err)
+		}
+	}
+	return t.store.PutBundle(newBundleRecord(tx, requestIDs))
+}
+
+// Check goes through all submitted bundles once.
+func (t *Tracker) Check(ctx context.Context) error {
+	bundles, err := t.store.SubmittedBundles()
+	if err != nil {
+		return xerrors.Errorf("failed to load submitted bundles: %w", err)
+	}
+	if len(bundles) == 0 {
+		return nil
+	}
+	head, err := t.backend.BlockNumber(ctx)
+	if err != nil {
+		return xerrors.Errorf("failed to get block number: %w", err)
+	}
+
+	for _, bundle := range bundles {
+		if err := t.check(ctx, head, bundle); err != nil {
+			l.Warnf("Failed to check bundle %s: %s", bundle.ID.Hex(), err.Error())
+		}
+	}
+	return nil
+}
+
+func (t *Tracker) check(ctx context.Context, head uint64, bundle BundleRecord) error {
+	// Nonce is checked before receipts, so that a transaction mined in between is not taken as dropped.
+	nonce, err := t.backend.NonceAt(ctx, bundle.From)
+	if err != nil {
+		return xerrors.Errorf("failed to get nonce: %w", err)
+	}
+
+	for i := len(bundle.Txs) - 1; i >= 0; i-- {
+		receipt, effectiveGasPrice, err := t.backend.Receipt(ctx, bundle.Txs[i])
+		if err != nil {
+			return xerrors.Errorf("failed to get receipt: %w", err)
+		}
+		if receipt != nil {
+			return t.finish(bundle, bundle.Txs[i], receipt, effectiveGasPrice)
+		}
+	}
+
+	if nonce > bundle.Nonce {
+		l.Warnf("Bundle %s dropped, nonce %d used by another transaction", bundle.ID.Hex(), bundle.Nonce)
+		bundle.Status = StatusDropped
+		bundle.Reason = "nonce used by another transaction"
+		bundle.UpdatedAt = time.Now()
+		for _, requestID := range bundle.RequestIDs {
+			setStatus(t.store, requestID, StatusDropped, "bundle transaction dropped: "+bundle.Reason, common.Hash{})
+		}
+		if err := t.backend.ResyncNonce(ctx, bundle.From); err != nil {
+			l.Warnf("Failed to resync nonce of %s: %s", bundle.From.Hex(), err.Error())
+		}
+		return t.done(bundle)
+	}
+
+	if bundle.SentBlock == 0 {
+		bundle.SentBlock = head
+		return t.store.PutBundle(bundle)
+	}
+	if head < bundle.SentBlock+t.resendAfterBlocks || bundle.Resends >= t.maxResends {
+		return nil
+	}
+
+	current := bundle.Txs[len(bundle.Txs)-1]
+	replacement, err := t.backend.Resend(ctx, current)
+	if err != nil {
+		return xerrors.Errorf("failed to resend: %w", err)
+	}
+	l.Infof("Bundle %s not mined after %d blocks, re-sent as %s", bundle.ID.Hex(), head-bundle.SentBlock, replacement.Hash().Hex())
+	bundle.Txs = append(bundle.Txs, replacement)
+	bundle.SentBlock = head
+	bundle.Resends++
+	bundle.UpdatedAt = time.Now()
+	for _, requestID := range bundle.RequestIDs {
+		setStatus(t.store, requestID, StatusSubmitted, "", replacement.Hash())
+	}
+	return t.store.PutBundle(bundle)
+}
+
+// finish records the mined transaction of bundle.
+func (t *Tracker) finish(bundle BundleRecord, tx *types.Transaction, receipt *types.Receipt, effectiveGasPrice *big.Int) error {
+	bundle.Status = StatusIncluded
+	opReason := ""
+	if receipt.Status != types.ReceiptStatusSuccessful {
+		bundle.Status = StatusReverted
+		opReason = "bundle transaction reverted"
+	}
+	bundle.TxHash = tx.Hash()
+	bundle.Receipt = receipt
+	bundle.GasUsed = receipt.GasUsed
+	bundle.EffectiveGasPrice = effectiveGasPrice
+	bundle.UpdatedAt = time.Now()
+	l.Infof("Bundle %s %s in tx %s, block %s, gas used %d", bundle.ID.Hex(), bundle.Status, tx.Hash().Hex(), receipt.BlockNumber, receipt.GasUsed)
+
+	for _, requestID := range bundle.RequestIDs {
+		setStatus(t.store, requestID, bundle.Status, opReason, tx.Hash())
+	}
+	return t.done(bundle)
+}
+
+// done saves bundle mined or dropped, or deletes it with its operations if evictFinished is set.
+func (t *Tracker) done(bundle BundleRecord) error {
+	if !t.evictFinished {
+		return t.store.PutBundle(bundle)
+	}
+	for _, requestID := range bundle.RequestIDs {
+		if err := t.store.Delete(requestID); err != nil {
+			return xerrors.Errorf("failed to delete operation %s: %w", requestID.Hex(), err)
+		}
+	}
+	return t.store.DeleteBundle(bundle.ID)
+}
+
+// Run checks submitted bundles every interval.
+// Blocks until ctx is done.
+func (t *Tracker) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		if err := t.Check(ctx); err != nil {
+			l.Errorf("%s", err.Error())
+		}
+	}
+}
@@ -0,0 +1,222 @@
+package mempool
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/require"
+
+	"bundler/abi"
+)
+
+type fakeBackend struct {
+	head  uint64
+	nonce uint64
+	// Mined transactions by hash.
+	receipts map[common.Hash]*types.Receipt
+	resent   []*types.Transaction
+	resynced []common.Address
+}
+
+func newFakeBackend() *fakeBackend {
+	return &fakeBackend{receipts: make(map[common.Hash]*types.Receipt)}
+}
+
+func (b *fakeBackend) BlockNumber(ctx context.Context) (uint64, error) {
+	return b.head, nil
+}
+
+func (b *fakeBackend) NonceAt(ctx context.Context, account common.Address) (uint64, error) {
+	return b.nonce, nil
+}
+
+func (b *fakeBackend) Receipt(ctx context.Context, tx *types.Transaction) (*types.Receipt, *big.Int, error) {
+	receipt, ok := b.receipts[tx.Hash()]
+	if !ok {
+		return nil, nil, nil
+	}
+	return receipt, big.NewInt(42), nil
+}
+
+func (b *fakeBackend) Resend(ctx context.Context, tx *types.Transaction) (*types.Transaction, error) {
+	replacement := signTx(tx.Nonce(), tx.GasTipCap().Int64()+1)
+	b.resent = append(b.resent, replacement)
+	return replacement, nil
+}
+
+func (b *fakeBackend) ResyncNonce(ctx context.Context, account common.Address) error {
+	b.resynced = append(b.resynced, account)
+	return nil
+}
+
+func (b *fakeBackend) mine(tx *types.Transaction, status uint64) {
+	b.receipts[tx.Hash()] = &types.Receipt{
+		Status:      status,
+		TxHash:      tx.Hash(),
+		GasUsed:     80000,
+		BlockNumber: big.NewInt(int64(b.head)),
+		Logs:        []*types.Log{},
+	}
+	b.nonce = tx.Nonce() + 1
+}
+
+func Test_Tracker(t *testing.T) {
+	before_each := func(store Store) (*Mempool, *fakeChain, *fakeBackend, *Tracker, common.Hash) {
+		chain := &fakeChain{}
+		pool := New(store, time.Hour, 10, chain.submit, chain.simulate)
+		requestID, op := newOp(common.HexToAddress("0x0a"), 0, 1)
+		require.NoError(t, pool.Add(requestID, op))
+		require.Equal(t, 1, pool.Bundle(context.Background()))
+
+		backend := newFakeBackend()
+		backend.head = 10
+		return pool, chain, backend, NewTracker(store, backend, 3, 1, false), requestID
+	}
+	ctx := context.Background()
+
+	t.Run("resend and include", func(t *testing.T) {
+		store, err := NewLevelDBStore(t.TempDir())
+		require.NoError(t, err)
+		defer store.Close()
+		pool, chain, backend, tracker, requestID := before_each(store)
+		bundleID := chain.txs[0].Hash()
+
+		require.NoError(t, tracker.Check(ctx))
+		bundle, err := store.GetBundle(bundleID)
+		require.NoError(t, err)
+		require.EqualValues(t, 10, bundle.SentBlock)
+
+		backend.head = 12
+		require.NoError(t, tracker.Check(ctx))
+		require.Len(t, backend.resent, 0)
+
+		backend.head = 13
+		require.NoError(t, tracker.Check(ctx))
+		require.Len(t, backend.resent, 1)
+		record, err := pool.Get(requestID)
+		require.NoError(t, err)
+		require.Equal(t, StatusSubmitted, record.Status)
+		require.Equal(t, backend.resent[0].Hash(), record.TxHash)
+
+		// No more than `maxResends`
+		backend.head = 20
+		require.NoError(t, tracker.Check(ctx))
+		require.Len(t, backend.resent, 1)
+
+		backend.mine(backend.resent[0], types.ReceiptStatusSuccessful)
+		require.NoError(t, tracker.Check(ctx))
+		bundle, err = store.GetBundle(bundleID)
+		require.NoError(t, err)
+		require.Equal(t, StatusIncluded, bundle.Status)
+		require.Equal(t, backend.resent[0].Hash(), bundle.TxHash)
+		require.Len(t, bundle.Txs, 2)
+		require.EqualValues(t, 80000, bundle.GasUsed)
+		require.EqualValues(t, 42, bundle.EffectiveGasPrice.Int64())
+		require.Equal(t, backend.resent[0].Hash(), bundle.Receipt.TxHash)
+		record, err = pool.Get(requestID)
+		require.NoError(t, err)
+		require.Equal(t, StatusIncluded, record.Status)
+
+		bundles, err := store.SubmittedBundles()
+		require.NoError(t, err)
+		require.Empty(t, bundles)
+	})
+
+	t.Run("reverted", func(t *testing.T) {
+		store := NewMemoryStore()
+		pool, chain, backend, tracker, requestID := before_each(store)
+
+		backend.mine(chain.txs[0], types.ReceiptStatusFailed)
+		require.NoError(t, tracker.Check(ctx))
+		bundle, err := store.GetBundle(chain.txs[0].Hash())
+		require.NoError(t, err)
+		require.Equal(t, StatusReverted, bundle.Status)
+		record, err := pool.Get(requestID)
+		require.NoError(t, err)
+		require.Equal(t, StatusReverted, record.Status)
+
+		// Nonce of the wallet is not used, so the operation can be sent again.
+		_, op := newOp(common.HexToAddress("0x0a"), 0, 1)
+		require.NoError(t, pool.Add(common.HexToHash("0x03"), op))
+	})
+
+	t.Run("dropped", func(t *testing.T) {
+		store := NewMemoryStore()
+		pool, chain, backend, tracker, requestID := before_each(store)
+
+		backend.nonce = 1
+		require.NoError(t, tracker.Check(ctx))
+		bundle, err := store.GetBundle(chain.txs[0].Hash())
+		require.NoError(t, err)
+		require.Equal(t, StatusDropped, bundle.Status)
+		record, err := pool.Get(requestID)
+		require.NoError(t, err)
+		require.Equal(t, StatusDropped, record.Status)
+		require.Contains(t, record.Reason, "nonce used by another transaction")
+		require.Equal(t, []common.Address{bundle.From}, backend.resynced)
+	})
+	t.Run("bundle sent without mempool", func(t *testing.T) {
+		store := NewMemoryStore()
+		backend := newFakeBackend()
+		backend.head = 10
+		tracker := NewTracker(store, backend, 3, 1, true)
+
+		requestID, op := newOp(common.HexToAddress("0x0a"), 0, 1)
+		tx := signTx(0, 1)
+		require.NoError(t, tracker.Track(tx, []common.Hash{requestID}, []abi.UserOperation{op}))
+		record, err := store.Get(requestID)
+		require.NoError(t, err)
+		require.Equal(t, StatusSubmitted, record.Status)
+
+		// Still submitted, so nothing is evicted.
+		require.NoError(t, tracker.Check(ctx))
+		bundle, err := store.GetBundle(tx.Hash())
+		require.NoError(t, err)
+		require.Equal(t, StatusSubmitted, bundle.Status)
+
+		// Mined bundle is evicted with its operations.
+		backend.mine(tx, types.ReceiptStatusSuccessful)
+		require.NoError(t, tracker.Check(ctx))
+		bundle, err = store.GetBundle(tx.Hash())
+		require.NoError(t, err)
+		require.Nil(t, bundle)
+		record, err = store.Get(requestID)
+		require.NoError(t, err)
+		require.Nil(t, record)
+		record, err = store.GetBySenderNonce(op.Sender, op.Nonce.String())
+		require.NoError(t, err)
+		require.Nil(t, record)
+	})
+
+	t.Run("dropped bundle sent without mempool", func(t *testing.T) {
+		store, err := NewLevelDBStore(t.TempDir())
+		require.NoError(t, err)
+		defer store.Close()
+		backend := newFakeBackend()
+		tracker := NewTracker(store, backend, 3, 1, true)
+
+		requestID, op := newOp(common.HexToAddress("0x0a"), 0, 1)
+		tx := signTx(0, 1)
+		require.NoError(t, tracker.Track(tx, []common.Hash{requestID}, []abi.UserOperation{op}))
+
+		backend.nonce = 1
+		require.NoError(t, tracker.Check(ctx))
+		bundle, err := store.GetBundle(tx.Hash())
+		require.NoError(t, err)
+		require.Nil(t, bundle)
+		record, err := store.Get(requestID)
+		require.NoError(t, err)
+		require.Nil(t, record)
+		record, err = store.GetBySenderNonce(op.Sender, op.Nonce.String())
+		require.NoError(t, err)
+		require.Nil(t, record)
+		bundles, err := store.SubmittedBundles()
+		require.NoError(t, err)
+		require.Empty(t, bundles)
+		require.Len(t, backend.resynced, 1)
+	})
+}
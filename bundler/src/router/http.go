@@ -0,0 +1,43 @@
+package router
+
+import (
+	"io"
+	"net/http"
+	"strings"
+)
+
+// maxBodySize limits request body read from net/http clients.
+// API Gateway already caps payloads at 10MB.
+const maxBodySize = 10 << 20
+
+// ServeHTTP makes Router usable as a net/http handler.
+func (r *Router) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	body, err := io.ReadAll(io.LimitReader(req.Body, maxBodySize))
+	if err != nil {
+		http.Error(w, "failed to read request body", http.StatusBadRequest)
+		return
+	}
+
+	headers := make(map[string]string, len(req.Header))
+	for key := range req.Header {
+		headers[strings.ToLower(key)] = req.Header.Get(key)
+	}
+	query := make(map[string]string)
+	for key := range req.URL.Query() {
+		query[key] = req.URL.Query().Get(key)
+	}
+
+	response := r.Serve(req.Context(), Request{
+		Method:  req.Method,
+		Path:    req.URL.Path,
+		Headers: headers,
+		Query:   query,
+		Body:    body,
+	})
+
+	for key, value := range response.Headers {
+		w.Header().Set(key, value)
+	}
+	w.WriteHeader(response.StatusCode)
+	_, _ = w.Write(response.Body)
+}
@@ -0,0 +1,99 @@
+package router
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+
+	"github.com/aws/aws-lambda-go/events"
+	"golang.org/x/xerrors"
+)
+
+// LambdaHandler accepts both API Gateway REST (payload format 1.0) and
+// HTTP API (payload format 2.0) events, since `template.yaml` routes
+// through both. Use it as `lambda.Start(r.LambdaHandler)`.
+func (r *Router) LambdaHandler(ctx context.Context, payload json.RawMessage) (any, error) {
+	version := struct {
+		Version string `json:"version"`
+	}{}
+	if err := json.Unmarshal(payload, &version); err != nil {
+		return nil, xerrors.Errorf("failed to parse lambda payload: %w", err)
+	}
+
+	if version.Version == "2.0" {
+		request := events.APIGatewayV2HTTPRequest{}
+		if err := json.Unmarshal(payload, &request); err != nil {
+			return nil, xerrors.Errorf("failed to parse API Gateway v2 request: %w", err)
+		}
+		return r.HandleAPIGatewayV2(ctx, request)
+	}
+
+	request := events.APIGatewayProxyRequest{}
+	if err := json.Unmarshal(payload, &request); err != nil {
+		return nil, xerrors.Errorf("failed to parse API Gateway v1 request: %w", err)
+	}
+	return r.HandleAPIGatewayV1(ctx, request)
+}
+
+// HandleAPIGatewayV1 serves an API Gateway REST proxy request.
+func (r *Router) HandleAPIGatewayV1(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	body, err := decodeLambdaBody(request.Body, request.IsBase64Encoded)
+	if err != nil {
+		return events.APIGatewayProxyResponse{}, err
+	}
+
+	path := request.Path
+	if proxy, ok := request.PathParameters["proxy"]; ok {
+		path = proxy
+	}
+
+	response := r.Serve(ctx, Request{
+		Method:  request.HTTPMethod,
+		Path:    path,
+		Headers: lowerKeys(request.Headers),
+		Query:   request.QueryStringParameters,
+		Body:    body,
+	})
+	return events.APIGatewayProxyResponse{
+		StatusCode: response.StatusCode,
+		Headers:    response.Headers,
+		Body:       string(response.Body),
+	}, nil
+}
+
+// HandleAPIGatewayV2 serves an API Gateway HTTP API (payload format 2.0) request.
+func (r *Router) HandleAPIGatewayV2(ctx context.Context, request events.APIGatewayV2HTTPRequest) (events.APIGatewayV2HTTPResponse, error) {
+	body, err := decodeLambdaBody(request.Body, request.IsBase64Encoded)
+	if err != nil {
+		return events.APIGatewayV2HTTPResponse{}, err
+	}
+
+	path := request.RawPath
+	if proxy, ok := request.PathParameters["proxy"]; ok {
+		path = proxy
+	}
+
+	response := r.Serve(ctx, Request{
+		Method:  request.RequestContext.HTTP.Method,
+		Path:    path,
+		Headers: lowerKeys(request.Headers),
+		Query:   request.QueryStringParameters,
+		Body:    body,
+	})
+	return events.APIGatewayV2HTTPResponse{
+		StatusCode: response.StatusCode,
+		Headers:    response.Headers,
+		Body:       string(response.Body),
+	}, nil
+}
+
+func decodeLambdaBody(body string, isBase64Encoded bool) ([]byte, error) {
+	if !isBase64Encoded {
+		return []byte(body), nil
+	}
+	result, err := base64.StdEncoding.DecodeString(body)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to decode base64 request body: %w", err)
+	}
+	return result, nil
+}
@@ -0,0 +1,87 @@
+// Package router dispatches bundler API requests without depending on
+// any particular transport. Adapters in this package translate API Gateway
+// (REST v1 / HTTP API v2) events and plain net/http requests into Request.
+package router
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+var (
+	l = logrus.WithField("module", "router")
+)
+
+type Request struct {
+	Method string
+	// Always starts with `/` and has no trailing `/`.
+	Path string
+	// Keys are lower-cased.
+	Headers map[string]string
+	Query   map[string]string
+	Body    []byte
+}
+
+type Response struct {
+	StatusCode int
+	Headers    map[string]string
+	Body       []byte
+}
+
+type HandlerFunc func(ctx context.Context, request Request) Response
+
+type Router struct {
+	routes map[string]HandlerFunc
+}
+
+func New() *Router {
+	return &Router{
+		routes: make(map[string]HandlerFunc),
+	}
+}
+
+// Handle registers handler for given path, e.g. `/healthz`.
+func (r *Router) Handle(path string, handler HandlerFunc) {
+	r.routes[normalizePath(path)] = handler
+}
+
+// Serve dispatches request to the handler registered for its path.
+func (r *Router) Serve(ctx context.Context, request Request) Response {
+	request.Path = normalizePath(request.Path)
+	handler, ok := r.routes[request.Path]
+	if !ok {
+		return JSON(http.StatusNotFound, map[string]string{"message": "not found: " + request.Path})
+	}
+	return handler(ctx, request)
+}
+
+// JSON builds a response with body marshaled from v.
+func JSON(statusCode int, v any) Response {
+	body, err := json.Marshal(v)
+	if err != nil {
+		l.Errorf("Failed to marshal response body: %s", err.Error())
+		statusCode = http.StatusInternalServerError
+		body, _ = json.Marshal(map[string]string{"message": "failed to marshal response body: " + err.Error()})
+	}
+	return Response{
+		StatusCode: statusCode,
+		Headers:    map[string]string{"Content-Type": "application/json"},
+		Body:       body,
+	}
+}
+
+func normalizePath(path string) string {
+	return "/" + strings.Trim(path, "/")
+}
+
+func lowerKeys(m map[string]string) map[string]string {
+	result := make(map[string]string, len(m))
+	for key, value := range m {
+		result[strings.ToLower(key)] = value
+	}
+	return result
+}
@@ -0,0 +1,105 @@
+package router
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/stretchr/testify/require"
+)
+
+func echoRouter() *Router {
+	r := New()
+	r.Handle("/echo", func(ctx context.Context, request Request) Response {
+		return JSON(http.StatusOK, map[string]string{
+			"method": request.Method,
+			"path":   request.Path,
+			"header": request.Headers["x-test"],
+			"body":   string(request.Body),
+		})
+	})
+	return r
+}
+
+func parseEcho(t *testing.T, body string) map[string]string {
+	result := map[string]string{}
+	require.NoError(t, json.Unmarshal([]byte(body), &result))
+	return result
+}
+
+func Test_Serve(t *testing.T) {
+	t.Run("not found", func(t *testing.T) {
+		resp := echoRouter().Serve(context.Background(), Request{Path: "/nothing"})
+		require.Equal(t, http.StatusNotFound, resp.StatusCode)
+	})
+
+	t.Run("trailing slash", func(t *testing.T) {
+		resp := echoRouter().Serve(context.Background(), Request{Path: "echo/"})
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		require.Equal(t, "/echo", parseEcho(t, string(resp.Body))["path"])
+	})
+}
+
+func Test_HandleAPIGatewayV1(t *testing.T) {
+	t.Run("proxy path", func(t *testing.T) {
+		resp, err := echoRouter().HandleAPIGatewayV1(context.Background(), events.APIGatewayProxyRequest{
+			HTTPMethod:      "POST",
+			Path:            "/Prod/echo",
+			PathParameters:  map[string]string{"proxy": "echo"},
+			Headers:         map[string]string{"X-Test": "v1"},
+			Body:            base64.StdEncoding.EncodeToString([]byte("hello")),
+			IsBase64Encoded: true,
+		})
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		echo := parseEcho(t, resp.Body)
+		require.Equal(t, "POST", echo["method"])
+		require.Equal(t, "v1", echo["header"])
+		require.Equal(t, "hello", echo["body"])
+	})
+}
+
+func Test_LambdaHandler(t *testing.T) {
+	t.Run("v2 payload", func(t *testing.T) {
+		payload := `{"version":"2.0","rawPath":"/echo","headers":{"x-test":"v2"},"body":"hello","requestContext":{"http":{"method":"POST"}}}`
+		resp, err := echoRouter().LambdaHandler(context.Background(), json.RawMessage(payload))
+		require.NoError(t, err)
+		v2, ok := resp.(events.APIGatewayV2HTTPResponse)
+		require.True(t, ok)
+		require.Equal(t, http.StatusOK, v2.StatusCode)
+		echo := parseEcho(t, v2.Body)
+		require.Equal(t, "POST", echo["method"])
+		require.Equal(t, "v2", echo["header"])
+		require.Equal(t, "hello", echo["body"])
+	})
+
+	t.Run("v1 payload", func(t *testing.T) {
+		payload := `{"httpMethod":"GET","path":"/echo","headers":{"X-Test":"v1"}}`
+		resp, err := echoRouter().LambdaHandler(context.Background(), json.RawMessage(payload))
+		require.NoError(t, err)
+		v1, ok := resp.(events.APIGatewayProxyResponse)
+		require.True(t, ok)
+		require.Equal(t, http.StatusOK, v1.StatusCode)
+		require.Equal(t, "v1", parseEcho(t, v1.Body)["header"])
+	})
+}
+
+func Test_ServeHTTP(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		req := httptest.NewRequest("POST", "/echo", strings.NewReader("hello"))
+		req.Header.Set("X-Test", "http")
+		w := httptest.NewRecorder()
+		echoRouter().ServeHTTP(w, req)
+
+		require.Equal(t, http.StatusOK, w.Code)
+		require.Equal(t, "application/json", w.Header().Get("Content-Type"))
+		echo := parseEcho(t, w.Body.String())
+		require.Equal(t, "http", echo["header"])
+		require.Equal(t, "hello", echo["body"])
+	})
+}
@@ -0,0 +1,99 @@
+package signer
+
+import (
+	"bytes"
+	"context"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+	"golang.org/x/xerrors"
+)
+
+type externalSigner struct {
+	client  *rpc.Client
+	url     string
+	address common.Address
+}
+
+// NewExternal creates a signer asking a Clef-compatible JSON-RPC endpoint to sign with `account_signTransaction`.
+// The key of address never enters bundler process.
+func NewExternal(url string, address common.Address) (Signer, error) {
+	client, err := rpc.Dial(url)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to connect to external signer %s: %w", url, err)
+	}
+	return &externalSigner{client: client, url: url, address: address}, nil
+}
+
+func (s *externalSigner) Address() common.Address {
+	return s.address
+}
+
+type signTransactionResult struct {
+	Raw hexutil.Bytes `json:"raw"`
+}
+
+func (s *externalSigner) SignTx(ctx context.Context, tx *types.Transaction, chainID *big.Int) (*types.Transaction, error) {
+	data := hexutil.Bytes(tx.Data())
+	args := apitypes.SendTxArgs{
+		From:    common.NewMixedcaseAddress(s.address),
+		Gas:     hexutil.Uint64(tx.Gas()),
+		Value:   hexutil.Big(*tx.Value()),
+		Nonce:   hexutil.Uint64(tx.Nonce()),
+		Data:    &data,
+		ChainID: (*hexutil.Big)(chainID),
+	}
+	if tx.To() != nil {
+		to := common.NewMixedcaseAddress(*tx.To())
+		args.To = &to
+	}
+	if tx.Type() == types.LegacyTxType {
+		args.GasPrice = (*hexutil.Big)(tx.GasPrice())
+	} else {
+		args.MaxFeePerGas = (*hexutil.Big)(tx.GasFeeCap())
+		args.MaxPriorityFeePerGas = (*hexutil.Big)(tx.GasTipCap())
+		accessList := tx.AccessList()
+		args.AccessList = &accessList
+	}
+
+	result := signTransactionResult{}
+	if err := s.client.CallContext(ctx, &result, "account_signTransaction", &args); err != nil {
+		return nil, xerrors.Errorf("external signer %s failed to sign: %w", s.url, err)
+	}
+	signed := &types.Transaction{}
+	if err := signed.UnmarshalBinary(result.Raw); err != nil {
+		return nil, xerrors.Errorf("failed to decode transaction signed by external signer: %w", err)
+	}
+
+	// Make sure the signer signed what was asked.
+	sender, err := types.Sender(types.LatestSignerForChainID(chainID), signed)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to recover sender of transaction signed by external signer: %w", err)
+	}
+	if sender != s.address || !sameTransaction(signed, tx, chainID) {
+		l.Errorf("External signer %s returned unexpected transaction %s", s.url, signed.Hash().Hex())
+		return nil, xerrors.Errorf("external signer %s returned a different transaction", s.url)
+	}
+	return signed, nil
+}
+
+// sameTransaction reports whether signed has every field of unsigned tx, on chainID.
+func sameTransaction(signed *types.Transaction, tx *types.Transaction, chainID *big.Int) bool {
+	if signed.Type() != tx.Type() || signed.ChainId().Cmp(chainID) != 0 {
+		return false
+	}
+	if signed.Nonce() != tx.Nonce() || signed.Gas() != tx.Gas() || !bytes.Equal(signed.Data(), tx.Data()) {
+		return false
+	}
+	if signed.Value().Cmp(tx.Value()) != 0 || signed.GasFeeCap().Cmp(tx.GasFeeCap()) != 0 || signed.GasTipCap().Cmp(tx.GasTipCap()) != 0 {
+		return false
+	}
+	if signed.To() == nil || tx.To() == nil {
+		return signed.To() == nil && tx.To() == nil
+	}
+	return *signed.To() == *tx.To()
+}
@@ -0,0 +1,22 @@
+package signer
+
+import (
+	"os"
+
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"golang.org/x/xerrors"
+)
+
+// NewKeystore decrypts a go-ethereum encrypted key file, e.g. one created by `geth account new`.
+// The key is held in memory afterwards.
+func NewKeystore(path string, password string) (Signer, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to read keystore file %s: %w", path, err)
+	}
+	key, err := keystore.DecryptKey(content, password)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to decrypt keystore file %s: %w", path, err)
+	}
+	return NewLocal(key.PrivateKey), nil
+}
@@ -0,0 +1,50 @@
+package signer
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/xerrors"
+)
+
+var (
+	l = logrus.WithField("module", "signer")
+)
+
+// Signer signs transactions of one bundler account.
+type Signer interface {
+	Address() common.Address
+	SignTx(ctx context.Context, tx *types.Transaction, chainID *big.Int) (*types.Transaction, error)
+}
+
+type localSigner struct {
+	key     *ecdsa.PrivateKey
+	address common.Address
+}
+
+// NewLocal creates a signer holding a raw private key in memory.
+func NewLocal(key *ecdsa.PrivateKey) Signer {
+	return &localSigner{key: key, address: crypto.PubkeyToAddress(key.PublicKey)}
+}
+
+// NewLocalFromHex parses a hex private key without `0x` prefix.
+func NewLocalFromHex(secretKey string) (Signer, error) {
+	key, err := crypto.HexToECDSA(secretKey)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to parse secret key: %w", err)
+	}
+	return NewLocal(key), nil
+}
+
+func (s *localSigner) Address() common.Address {
+	return s.address
+}
+
+func (s *localSigner) SignTx(ctx context.Context, tx *types.Transaction, chainID *big.Int) (*types.Transaction, error) {
+	return types.SignTx(tx, types.LatestSignerForChainID(chainID), s.key)
+}
@@ -0,0 +1,141 @@
+package signer
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"math/big"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+	"github.com/stretchr/testify/require"
+)
+
+var chainID = big.NewInt(1337)
+
+func unsignedTx() *types.Transaction {
+	to := common.HexToAddress("0x0a")
+	return types.NewTx(&types.DynamicFeeTx{
+		ChainID:   chainID,
+		Nonce:     3,
+		GasTipCap: big.NewInt(1),
+		GasFeeCap: big.NewInt(100),
+		Gas:       100000,
+		To:        &to,
+		Data:      []byte{0x01, 0x02},
+	})
+}
+
+func requireSignedBy(t *testing.T, tx *types.Transaction, address common.Address) {
+	sender, err := types.Sender(types.LatestSignerForChainID(chainID), tx)
+	require.NoError(t, err)
+	require.Equal(t, address, sender)
+	require.EqualValues(t, 3, tx.Nonce())
+	require.Equal(t, []byte{0x01, 0x02}, tx.Data())
+}
+
+// fakeClef serves `account_signTransaction` like Clef with auto-approval.
+type fakeClef struct {
+	key *ecdsa.PrivateKey
+	// Changes the transaction before signing if set.
+	cheat func(args *apitypes.SendTxArgs)
+}
+
+func (c *fakeClef) SignTransaction(ctx context.Context, args apitypes.SendTxArgs) (*signTransactionResult, error) {
+	if c.cheat != nil {
+		c.cheat(&args)
+	}
+	tx, err := types.SignTx(args.ToTransaction(), types.LatestSignerForChainID((*big.Int)(args.ChainID)), c.key)
+	if err != nil {
+		return nil, err
+	}
+	raw, err := tx.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	return &signTransactionResult{Raw: raw}, nil
+}
+
+func Test_Signer(t *testing.T) {
+	ctx := context.Background()
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	address := crypto.PubkeyToAddress(key.PublicKey)
+
+	t.Run("local", func(t *testing.T) {
+		s := NewLocal(key)
+		require.Equal(t, address, s.Address())
+		tx, err := s.SignTx(ctx, unsignedTx(), chainID)
+		require.NoError(t, err)
+		requireSignedBy(t, tx, address)
+	})
+
+	t.Run("keystore", func(t *testing.T) {
+		ks := keystore.NewKeyStore(t.TempDir(), keystore.LightScryptN, keystore.LightScryptP)
+		account, err := ks.ImportECDSA(key, "password")
+		require.NoError(t, err)
+
+		_, err = NewKeystore(account.URL.Path, "wrong")
+		require.Error(t, err)
+
+		s, err := NewKeystore(account.URL.Path, "password")
+		require.NoError(t, err)
+		require.Equal(t, address, s.Address())
+		tx, err := s.SignTx(ctx, unsignedTx(), chainID)
+		require.NoError(t, err)
+		requireSignedBy(t, tx, address)
+	})
+
+	t.Run("external", func(t *testing.T) {
+		clef := &fakeClef{key: key}
+		server := rpc.NewServer()
+		require.NoError(t, server.RegisterName("account", clef))
+		httpServer := httptest.NewServer(server)
+		defer httpServer.Close()
+
+		s, err := NewExternal(httpServer.URL, address)
+		require.NoError(t, err)
+		require.Equal(t, address, s.Address())
+		tx, err := s.SignTx(ctx, unsignedTx(), chainID)
+		require.NoError(t, err)
+		requireSignedBy(t, tx, address)
+		require.Equal(t, big.NewInt(100), tx.GasFeeCap())
+
+		cheats := map[string]func(args *apitypes.SendTxArgs){
+			"nonce": func(args *apitypes.SendTxArgs) { args.Nonce++ },
+			"gas":   func(args *apitypes.SendTxArgs) { args.Gas++ },
+			"data":  func(args *apitypes.SendTxArgs) { *args.Data = append(*args.Data, 0x03) },
+			"to": func(args *apitypes.SendTxArgs) {
+				to := common.NewMixedcaseAddress(common.HexToAddress("0x0b"))
+				args.To = &to
+			},
+			"contract creation": func(args *apitypes.SendTxArgs) { args.To = nil },
+			"value":             func(args *apitypes.SendTxArgs) { args.Value = hexutil.Big(*big.NewInt(1)) },
+			"max fee":           func(args *apitypes.SendTxArgs) { args.MaxFeePerGas = (*hexutil.Big)(big.NewInt(1000)) },
+			"priority fee":      func(args *apitypes.SendTxArgs) { args.MaxPriorityFeePerGas = (*hexutil.Big)(big.NewInt(10)) },
+		}
+		for name, cheat := range cheats {
+			clef.cheat = cheat
+			_, err = s.SignTx(ctx, unsignedTx(), chainID)
+			require.ErrorContains(t, err, "different transaction", name)
+		}
+
+		// Signed for another chain, so the sender cannot be recovered.
+		clef.cheat = func(args *apitypes.SendTxArgs) { args.ChainID = (*hexutil.Big)(big.NewInt(1)) }
+		_, err = s.SignTx(ctx, unsignedTx(), chainID)
+		require.Error(t, err)
+
+		// Key not held by external signer
+		clef.cheat = nil
+		s, err = NewExternal(httpServer.URL, common.HexToAddress("0x0b"))
+		require.NoError(t, err)
+		_, err = s.SignTx(ctx, unsignedTx(), chainID)
+		require.Error(t, err)
+	})
+}